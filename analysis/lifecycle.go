@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"github.com/kaloszer/insulationCalcHtmx/calculations"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// BaseUValue returns the U-value of the construction without the added
+// layers of a calculated build-up
+func BaseUValue(result models.InsulationResult) float64 {
+	result.Layers = nil
+
+	return calculations.Evaluate(result).TotalUValue
+}
+
+// AnnualEnergySaved returns the delivered heating energy saved in kWh/m² a
+// year by lowering the U-value from base to improved: ΔU · degree-days · 24 h,
+// divided by the efficiency of the heating system
+func AnnualEnergySaved(base, improved float64, inputs models.EconomicInputs) float64 {
+	if inputs.Efficiency <= 0 {
+		return 0
+	}
+
+	return (base - improved) * inputs.DegreeDays * 24 / 1000 / inputs.Efficiency
+}
+
+// Lifecycle works out the savings, payback and net present value of the
+// added layers of a calculated build-up. The energy price rises by the
+// escalation rate every year and savings in year t are discounted by
+// (1 + discount rate)^t.
+func Lifecycle(result models.InsulationResult, inputs models.EconomicInputs) models.LifecycleAnalysis {
+	lifecycle := models.LifecycleAnalysis{
+		Inputs:            inputs,
+		BaseUValue:        BaseUValue(result),
+		SimplePayback:     -1,
+		DiscountedPayback: -1,
+	}
+
+	lifecycle.AnnualEnergySaved = AnnualEnergySaved(lifecycle.BaseUValue, result.TotalUValue, inputs)
+	lifecycle.AnnualSavings = lifecycle.AnnualEnergySaved * inputs.EnergyPrice
+
+	if lifecycle.AnnualSavings > 0 {
+		lifecycle.SimplePayback = result.TotalCost / lifecycle.AnnualSavings
+	}
+
+	saving := lifecycle.AnnualSavings
+	discount := 1.0
+	for year := 1; year <= inputs.AnalysisPeriod; year++ {
+		discount *= 1 + inputs.DiscountRate
+		present := saving / discount
+
+		// Interpolate within the year the investment is recovered
+		if lifecycle.DiscountedPayback < 0 && present > 0 && lifecycle.PresentSavings+present >= result.TotalCost {
+			lifecycle.DiscountedPayback = float64(year-1) + (result.TotalCost-lifecycle.PresentSavings)/present
+		}
+
+		lifecycle.PresentSavings += present
+		saving *= 1 + inputs.Escalation
+	}
+
+	lifecycle.NPV = lifecycle.PresentSavings - result.TotalCost
+
+	return lifecycle
+}
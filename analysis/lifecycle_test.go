@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/calculations"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// insulatedWall is 200 mm of concrete, U = 1/0.27, with 100 mm of insulation
+// λ 0.04 added at price per m³, U = 1/2.77
+func insulatedWall(price float64) models.InsulationResult {
+	return calculations.Evaluate(models.InsulationResult{
+		BaseLayers: []models.InsulationLayer{{Material: models.Material{Name: "Concrete", Lambda: 2.0}, Thickness: 200}},
+		Layers:     []models.InsulationLayer{{Material: models.Material{Name: "EPS", Lambda: 0.04, Price: price}, Thickness: 100}},
+		HeatFlow:   models.HeatFlowHorizontal,
+	})
+}
+
+func TestLifecycle(t *testing.T) {
+	// ΔU = 3.342690 W/m²K over 3000 Kd at 90 % saves
+	// 3.342690 · 3000 · 24 / 1000 / 0.9 = 267.4154 kWh/m² a year,
+	// 26.74154 a year at 0.10 per kWh
+	inputs := models.EconomicInputs{DegreeDays: 3000, Efficiency: 0.9, EnergyPrice: 0.1, AnalysisPeriod: 20}
+	escalating := inputs
+	escalating.Escalation, escalating.DiscountRate = 0.02, 0.05
+
+	tests := []struct {
+		name       string
+		result     models.InsulationResult
+		inputs     models.EconomicInputs
+		simple     float64
+		discounted float64
+		present    float64
+	}{
+		{
+			// 10 / 26.74154, then 20 years of savings
+			name:       "no discounting",
+			result:     insulatedWall(100),
+			inputs:     inputs,
+			simple:     0.373950,
+			discounted: 0.373950,
+			present:    534.8309,
+		},
+		{
+			// Σ 26.74154 · 1.02^(t-1) / 1.05^t over 20 years, recovered
+			// within the first year at 26.74154 / 1.05
+			name:       "escalation and discounting",
+			result:     insulatedWall(100),
+			inputs:     escalating,
+			simple:     0.373950,
+			discounted: 0.392648,
+			present:    392.1755,
+		},
+		{
+			name:       "not recovered within the analysis period",
+			result:     insulatedWall(100000),
+			inputs:     inputs,
+			simple:     373.950,
+			discounted: -1,
+			present:    534.8309,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lifecycle := Lifecycle(tt.result, tt.inputs)
+			if math.Abs(lifecycle.BaseUValue-1/0.27) > 1e-9 {
+				t.Errorf("base U = %.6f, want %.6f", lifecycle.BaseUValue, 1/0.27)
+			}
+			if math.Abs(lifecycle.AnnualEnergySaved-267.4154) > 1e-4 {
+				t.Errorf("energy saved = %.4f kWh/m², want 267.4154", lifecycle.AnnualEnergySaved)
+			}
+			if math.Abs(lifecycle.SimplePayback-tt.simple) > 1e-3 {
+				t.Errorf("simple payback = %.6f years, want %.6f", lifecycle.SimplePayback, tt.simple)
+			}
+			if math.Abs(lifecycle.DiscountedPayback-tt.discounted) > 1e-6 {
+				t.Errorf("discounted payback = %.6f years, want %.6f", lifecycle.DiscountedPayback, tt.discounted)
+			}
+			if math.Abs(lifecycle.PresentSavings-tt.present) > 1e-3 {
+				t.Errorf("present savings = %.4f, want %.4f", lifecycle.PresentSavings, tt.present)
+			}
+			if want := tt.present - tt.result.TotalCost; math.Abs(lifecycle.NPV-want) > 1e-3 {
+				t.Errorf("NPV = %.4f, want %.4f", lifecycle.NPV, want)
+			}
+			if lifecycle.PaysBack() != (tt.discounted >= 0) {
+				t.Errorf("pays back = %v", lifecycle.PaysBack())
+			}
+		})
+	}
+}
+
+func TestLifecycleWithoutSavings(t *testing.T) {
+	result := insulatedWall(100)
+	result.Layers = nil
+	result = calculations.Evaluate(result)
+
+	lifecycle := Lifecycle(result, models.EconomicInputs{DegreeDays: 3000, Efficiency: 0.9, EnergyPrice: 0.1, AnalysisPeriod: 20})
+	if lifecycle.AnnualSavings != 0 || lifecycle.SimplePayback != -1 || lifecycle.DiscountedPayback != -1 || lifecycle.NPV != 0 {
+		t.Errorf("nothing added should save nothing, got %+v", lifecycle)
+	}
+}
+
+func TestAnnualEnergySaved(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs models.EconomicInputs
+		saved  float64
+	}{
+		// (1.0 - 0.2) · 3500 · 24 / 1000 / 0.8
+		{"gas boiler", models.EconomicInputs{DegreeDays: 3500, Efficiency: 0.8}, 84},
+		{"heat pump", models.EconomicInputs{DegreeDays: 3500, Efficiency: 3.0}, 22.4},
+		{"no efficiency", models.EconomicInputs{DegreeDays: 3500}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnnualEnergySaved(1.0, 0.2, tt.inputs); math.Abs(got-tt.saved) > 1e-9 {
+				t.Errorf("saved = %.6f kWh/m², want %.6f", got, tt.saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package calculations
+
+import (
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// Thicknesses in mm of ISO 6946 Table 8
+var airLayerThicknesses = []float64{0, 5, 7, 10, 15, 25, 50, 100, 300}
+
+// Thermal resistance in m²K/W of unventilated air layers between surfaces
+// with a high emissivity, ISO 6946 Table 8
+var airLayerResistances = map[models.HeatFlowDirection][]float64{
+	models.HeatFlowUpward:     {0, 0.11, 0.13, 0.15, 0.16, 0.16, 0.16, 0.16, 0.16},
+	models.HeatFlowHorizontal: {0, 0.11, 0.13, 0.15, 0.17, 0.18, 0.18, 0.18, 0.18},
+	models.HeatFlowDownward:   {0, 0.11, 0.13, 0.15, 0.17, 0.19, 0.21, 0.22, 0.23},
+}
+
+// Above this resistance between a slightly ventilated air layer and the
+// outside, the excess is not counted, ISO 6946 clause 6.9.3
+const maxSlightlyVentilatedOuterResistance = 0.15
+
+// AirLayerResistance returns the design resistance of an air layer with the
+// thickness in mm, interpolated linearly in ISO 6946 Table 8. Layers thicker
+// than 300 mm get the 300 mm value. Slightly ventilated layers count half,
+// well ventilated ones nothing.
+func AirLayerResistance(thickness float64, direction models.HeatFlowDirection, ventilation models.VentilationClass) float64 {
+	resistances, ok := airLayerResistances[direction]
+	if !ok {
+		resistances = airLayerResistances[models.HeatFlowHorizontal]
+	}
+
+	resistance := resistances[len(resistances)-1]
+	for i := 1; i < len(airLayerThicknesses); i++ {
+		if thickness <= airLayerThicknesses[i] {
+			low, high := airLayerThicknesses[i-1], airLayerThicknesses[i]
+			resistance = resistances[i-1] + (resistances[i]-resistances[i-1])*(math.Max(thickness, 0)-low)/(high-low)
+			break
+		}
+	}
+
+	switch ventilation {
+	case models.SlightlyVentilated:
+		return resistance / 2
+	case models.WellVentilated:
+		return 0
+	default:
+		return resistance
+	}
+}
+
+// applyAirLayers applies the rules for ventilated air layers to evaluated
+// layers, interior first, and returns the exterior surface resistance to use.
+// A well-ventilated air layer and everything outside it are left out, and the
+// exterior surface resistance becomes that of the interior side (clause
+// 6.9.4). Outside a slightly ventilated one, at most 0.15 m²K/W counts.
+func applyAirLayers(layers []models.InsulationLayer, rsi, rse float64) float64 {
+	for i, layer := range layers {
+		if layer.Air == nil {
+			continue
+		}
+
+		switch layer.Air.Ventilation {
+		case models.WellVentilated:
+			for j := i; j < len(layers); j++ {
+				layers[j].Resistance = 0
+				layers[j].Excluded = true
+			}
+			return rsi
+		case models.SlightlyVentilated:
+			limitOuterResistance(layers[i+1:], rse)
+		}
+	}
+
+	return rse
+}
+
+// limitOuterResistance scales the homogeneous layers outside a slightly
+// ventilated air layer down so that, with Rse, they reach no more than
+// maxSlightlyVentilatedOuterResistance
+func limitOuterResistance(outer []models.InsulationLayer, rse float64) {
+	resistance := 0.0
+	for _, layer := range outer {
+		if !layer.Bridged() {
+			resistance += layer.Resistance
+		}
+	}
+	if resistance <= 0 || resistance+rse <= maxSlightlyVentilatedOuterResistance {
+		return
+	}
+
+	factor := math.Max(maxSlightlyVentilatedOuterResistance-rse, 0) / resistance
+	for i, layer := range outer {
+		if !layer.Bridged() {
+			outer[i].Resistance *= factor
+		}
+	}
+}
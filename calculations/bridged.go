@@ -0,0 +1,110 @@
+package calculations
+
+import (
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// BridgedLambda returns the thermal conductivity of an inhomogeneous layer
+// averaged over its area, the one used for the lower bound of ISO 6946
+// clause 6.7.2.3
+//
+//	λ'' = (1 - f)·λa + f·λb
+func BridgedLambda(layer models.InsulationLayer, conditions *models.LambdaConditions) float64 {
+	primary := DesignLambda(layer.Material, conditions)
+	if !layer.Bridged() {
+		return primary
+	}
+
+	fraction := layer.Bridge.Fraction
+
+	return (1-fraction)*primary + fraction*DesignLambda(layer.Bridge.Material, conditions)
+}
+
+// CombinedResistance applies the combined method of ISO 6946 clause 6.7.2 to
+// layers that are already evaluated. Each bridged layer splits every heat-flow
+// path in two, so the bridges of different layers are taken to cross each
+// other at random.
+//
+//	upper bound  1/R'T = Σ fm / RTm
+//	lower bound  R''T  = Rsi + Σ dj/λ''j + Rse
+//	RT = (R'T + R''T) / 2,  e = (R'T - R''T) / 2RT
+func CombinedResistance(rsi, rse float64, layers []models.InsulationLayer, conditions *models.LambdaConditions) models.ResistanceBounds {
+	sections := []models.ResistanceSection{{Fraction: 1, Resistance: rsi + rse}}
+	lower := rsi + rse
+
+	for _, layer := range layers {
+		lower += layer.Resistance
+		if !layer.Bridged() || layer.Excluded {
+			for i := range sections {
+				sections[i].Resistance += layer.Resistance
+			}
+			continue
+		}
+
+		fraction := layer.Bridge.Fraction
+		primary := LayerResistance(layer.Thickness, DesignLambda(layer.Material, conditions))
+		bridge := LayerResistance(layer.Thickness, DesignLambda(layer.Bridge.Material, conditions))
+
+		split := make([]models.ResistanceSection, 0, 2*len(sections))
+		for _, section := range sections {
+			if fraction < 1 {
+				split = append(split, models.ResistanceSection{
+					Fraction:   section.Fraction * (1 - fraction),
+					Resistance: section.Resistance + primary,
+				})
+			}
+			split = append(split, models.ResistanceSection{
+				Fraction:   section.Fraction * fraction,
+				Resistance: section.Resistance + bridge,
+			})
+		}
+		sections = split
+	}
+
+	return resistanceBounds(sections, lower)
+}
+
+// withAddedResistance returns the bounds after adding a homogeneous layer of
+// the given resistance, which adds to every section and to the lower bound
+func withAddedResistance(bounds models.ResistanceBounds, added float64) models.ResistanceBounds {
+	sections := make([]models.ResistanceSection, len(bounds.Sections))
+	for i, section := range bounds.Sections {
+		sections[i] = models.ResistanceSection{Fraction: section.Fraction, Resistance: section.Resistance + added}
+	}
+
+	return resistanceBounds(sections, bounds.Lower+added)
+}
+
+func resistanceBounds(sections []models.ResistanceSection, lower float64) models.ResistanceBounds {
+	conductance := 0.0
+	for _, section := range sections {
+		if section.Resistance > 0 {
+			conductance += section.Fraction / section.Resistance
+		}
+	}
+
+	bounds := models.ResistanceBounds{Lower: lower, Sections: sections}
+	if conductance > 0 {
+		bounds.Upper = 1 / conductance
+	}
+	if total := bounds.Total(); total > 0 {
+		bounds.RelativeError = (bounds.Upper - bounds.Lower) / (2 * total)
+	}
+
+	return bounds
+}
+
+// spreadCorrection raises the resistances of the counted bridged layers by their
+// share of correction, in proportion to their lower bound resistance, so the
+// layers add up to RT again
+func spreadCorrection(layers []models.InsulationLayer, correction, bridgedResistance float64) {
+	if bridgedResistance <= 0 {
+		return
+	}
+
+	for i, layer := range layers {
+		if layer.Bridged() && !layer.Excluded {
+			layers[i].Resistance += correction * layer.Resistance / bridgedResistance
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package calculations
+
+import (
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// ElementUValue returns the U-value of an envelope element: Uw of its window
+// product, calculated from its layers, or the value entered for elements
+// without layers. Ground floors are always calculated, even without layers.
+func ElementUValue(element models.BuildingElement) float64 {
+	if element.Window != nil {
+		return WindowU(element.Window.Product).UValue
+	}
+	if len(element.Layers) == 0 && element.Type != models.ElementGroundFloor {
+		return element.UValue
+	}
+
+	return Evaluate(element.Construction().Result()).TotalUValue
+}
+
+// BuildingHeatLoss sums A·U over the envelope, adds the junctions Σ ψ·l
+// placed along the elements and the thermal-bridge allowance ΔU_TB · ΣA over
+// the elements without junctions to get H_T, and multiplies it with the
+// design temperature difference to get the design transmission heat load.
+// Elements with junctions leave the allowance out, so their bridges do not
+// count twice.
+func BuildingHeatLoss(building models.Building) models.HeatLoss {
+	loss := models.HeatLoss{
+		Elements:              make([]models.ElementHeatLoss, 0, len(building.Elements)),
+		TemperatureDifference: building.IndoorTemperature - building.OutdoorTemperature,
+	}
+
+	for _, element := range building.Elements {
+		uValue := ElementUValue(element)
+		heatTransfer := element.Area * uValue
+		junctions := JunctionHeatTransfer(element.Junctions)
+
+		loss.Elements = append(loss.Elements, models.ElementHeatLoss{
+			Element:      element,
+			UValue:       uValue,
+			HeatTransfer: heatTransfer,
+			Junctions:    junctions,
+		})
+		loss.Area += element.Area
+		if len(element.Junctions) == 0 {
+			loss.AllowanceArea += element.Area
+		}
+		loss.Transmission += heatTransfer
+		loss.Junctions += junctions
+	}
+
+	loss.ThermalBridges = building.ThermalBridgeAllowance * loss.AllowanceArea
+	loss.HT = loss.Transmission + loss.ThermalBridges + loss.Junctions
+	loss.DesignLoad = loss.HT * loss.TemperatureDifference
+
+	return loss
+}
+
+// JunctionHeatTransfer sums ψ·l over the junctions placed along an element
+func JunctionHeatTransfer(junctions []models.PlacedJunction) float64 {
+	total := 0.0
+	for _, placed := range junctions {
+		total += placed.HeatTransfer()
+	}
+	return total
+}
+
+// ElementJunctionLoss spreads Σ ψ·l of the junctions along an element of the
+// given area and U-value over it, U + Σ ψ·l / A
+func ElementJunctionLoss(uValue, area float64, junctions []models.PlacedJunction) models.ElementJunctions {
+	loss := models.ElementJunctions{
+		Area:         area,
+		Junctions:    junctions,
+		HeatTransfer: JunctionHeatTransfer(junctions),
+		UValue:       uValue,
+	}
+	if area > 0 {
+		loss.UValue += loss.HeatTransfer / area
+	}
+	return loss
+}
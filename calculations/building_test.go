@@ -0,0 +1,78 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// placed puts a junction with the given ψ along length m
+func placed(psi, length float64) models.PlacedJunction {
+	return models.PlacedJunction{Junction: models.Junction{Psi: psi}, Length: length}
+}
+
+func TestElementUValue(t *testing.T) {
+	window := models.WindowProduct{Width: 1230, Height: 1480, FrameWidth: 100, Ug: 0.6, Uf: 1.0, PsiG: 0.04}
+	tests := []struct {
+		name    string
+		element models.BuildingElement
+		uValue  float64
+	}{
+		{"entered U-value", models.BuildingElement{Type: models.ElementWall, UValue: 0.25}, 0.25},
+		{"layers over the entered U-value", models.BuildingElement{Type: models.ElementWall, UValue: 0.25, Layers: []models.InsulationLayer{layer("Mineral wool", 200, 0.04)}}, 1 / 5.17},
+		{"window product", models.BuildingElement{Type: models.ElementWindow, UValue: 1.3, Window: &models.PlacedWindow{Product: window, Count: 1}}, 0.811822},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertClose(t, "U", ElementUValue(tt.element), tt.uValue, 1e-6)
+		})
+	}
+}
+
+func TestBuildingHeatLoss(t *testing.T) {
+	// The wall carries the ΔU_TB allowance, the roof its junctions instead
+	//
+	//	Σ A·U = 100 · 0.2 + 50 · 0.15 = 27.5 W/K
+	//	ΔU_TB · A = 0.05 · 100 = 5 W/K,  Σ ψ·l = 0.1 · 20 = 2 W/K
+	building := models.Building{
+		IndoorTemperature:      20,
+		OutdoorTemperature:     -20,
+		ThermalBridgeAllowance: 0.05,
+		Elements: []models.BuildingElement{
+			{Name: "Wall", Type: models.ElementWall, Area: 100, UValue: 0.2},
+			{Name: "Roof", Type: models.ElementRoof, Area: 50, UValue: 0.15, Junctions: []models.PlacedJunction{placed(0.1, 20)}},
+		},
+	}
+	loss := BuildingHeatLoss(building)
+
+	assertClose(t, "A", loss.Area, 150, 1e-9)
+	assertClose(t, "allowance area", loss.AllowanceArea, 100, 1e-9)
+	assertClose(t, "Σ A·U", loss.Transmission, 27.5, 1e-9)
+	assertClose(t, "ΔU_TB · A", loss.ThermalBridges, 5, 1e-9)
+	assertClose(t, "Σ ψ·l", loss.Junctions, 2, 1e-9)
+	assertClose(t, "H_T", loss.HT, 34.5, 1e-9)
+	assertClose(t, "design load", loss.DesignLoad, 1380, 1e-9)
+}
+
+func TestElementJunctionLoss(t *testing.T) {
+	junctions := []models.PlacedJunction{placed(0.55, 4), placed(0.65, 2.5)}
+	tests := []struct {
+		name         string
+		area         float64
+		heatTransfer float64
+		uValue       float64
+	}{
+		// 0.191 + (0.55 · 4 + 0.65 · 2.5) / 10
+		{"spread over the element", 10, 3.825, 0.5735},
+		{"no area to spread over", 0, 3.825, 0.191},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loss := ElementJunctionLoss(0.191, tt.area, junctions)
+			assertClose(t, "Σ ψ·l", loss.HeatTransfer, tt.heatTransfer, 1e-9)
+			assertClose(t, "U incl. junctions", loss.UValue, tt.uValue, 1e-9)
+		})
+	}
+}
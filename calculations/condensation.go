@@ -0,0 +1,274 @@
+package calculations
+
+import (
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// δ0, water vapour permeability of still air in kg/(m·s·Pa), ISO 13788
+const vapourPermeabilityOfAir = 2e-10
+
+// Layers are split into sub-layers of at most this thermal resistance so
+// that condensation inside a thick layer is not missed (ISO 13788, 6.2)
+const maxSubLayerResistance = 0.25
+
+var daysInMonth = [12]float64{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// SaturationPressure returns the saturation water vapour pressure in Pa at
+// the given temperature in °C, ISO 13788 Annex E
+func SaturationPressure(temperature float64) float64 {
+	if temperature >= 0 {
+		return 610.5 * math.Exp(17.269*temperature/(237.3+temperature))
+	}
+
+	return 610.5 * math.Exp(21.875*temperature/(265.5+temperature))
+}
+
+// glaserPlane is a plane of the construction before boundary conditions
+// are applied
+type glaserPlane struct {
+	position   float64 // mm from the interior surface
+	resistance float64 // thermal resistance from the indoor air, m²K/W
+	sd         float64 // equivalent air thickness from the interior surface, m
+}
+
+// glaserPlanes returns the interior surface, every layer boundary and the
+// sub-layer planes, ending with the exterior surface
+func glaserPlanes(result models.InsulationResult) []glaserPlane {
+	planes := []glaserPlane{{resistance: result.InternalResistance}}
+
+	for _, layer := range result.AllLayers() {
+		parts := int(math.Ceil(layer.Resistance / maxSubLayerResistance))
+		if parts < 1 {
+			parts = 1
+		}
+
+		last := planes[len(planes)-1]
+		for i := 1; i <= parts; i++ {
+			fraction := float64(i) / float64(parts)
+			planes = append(planes, glaserPlane{
+				position:   last.position + fraction*layer.Thickness,
+				resistance: last.resistance + fraction*layer.Resistance,
+				sd:         last.sd + fraction*layer.EquivalentAirThickness(),
+			})
+		}
+	}
+
+	return planes
+}
+
+// glaserState is the temperature and vapour pressure field for one set of
+// boundary conditions
+type glaserState struct {
+	temperature        []float64
+	saturationPressure []float64
+	vapourPressure     []float64
+	rates              []float64 // kg/(m²·s), positive when condensing
+}
+
+// solveGlaser computes the temperature and vapour pressure at every plane.
+// Planes marked wet still hold condensate and are kept at saturation.
+func solveGlaser(planes []glaserPlane, totalResistance float64, indoorTemperature, indoorHumidity, outdoorTemperature, outdoorHumidity float64, wet []bool) glaserState {
+	n := len(planes)
+	state := glaserState{
+		temperature:        make([]float64, n),
+		saturationPressure: make([]float64, n),
+		rates:              make([]float64, n),
+	}
+
+	sd := make([]float64, n)
+	for i, plane := range planes {
+		state.temperature[i] = temperatureAt(plane.resistance, totalResistance, indoorTemperature, outdoorTemperature)
+		state.saturationPressure[i] = SaturationPressure(state.temperature[i])
+		sd[i] = plane.sd
+	}
+
+	indoorPressure := indoorHumidity * SaturationPressure(indoorTemperature)
+	outdoorPressure := outdoorHumidity * SaturationPressure(outdoorTemperature)
+	state.vapourPressure = vapourProfile(sd, state.saturationPressure, indoorPressure, outdoorPressure, wet)
+
+	for k := 1; k < n-1; k++ {
+		onCurve := state.vapourPressure[k] >= state.saturationPressure[k]-1e-6
+		if !onCurve && !wet[k] {
+			continue
+		}
+
+		in := vapourFlux(sd[k-1], sd[k], state.vapourPressure[k-1], state.vapourPressure[k])
+		out := vapourFlux(sd[k], sd[k+1], state.vapourPressure[k], state.vapourPressure[k+1])
+		state.rates[k] = in - out
+	}
+
+	return state
+}
+
+// vapourProfile returns the vapour pressure at every plane: the straight
+// line between the indoor and outdoor pressures, pulled down onto the
+// saturation pressure wherever it would exceed it (the Glaser tangents).
+func vapourProfile(sd, saturation []float64, indoor, outdoor float64, wet []bool) []float64 {
+	n := len(sd)
+	pressure := make([]float64, n)
+	pressure[0] = indoor
+	pressure[n-1] = outdoor
+
+	ceiling := func(k int) float64 {
+		if k == n-1 {
+			return outdoor
+		}
+		return saturation[k]
+	}
+
+	start := 0
+	for start < n-1 {
+		// A wet plane is pinned to saturation, so the line may not pass it
+		end := n - 1
+		for k := start + 1; k < n-1; k++ {
+			if wet[k] {
+				end = k
+				break
+			}
+		}
+
+		// The line bends at the plane with the lowest slope from start
+		next := end
+		nextSlope := slope(sd[start], sd[end], pressure[start], ceiling(end))
+		for k := start + 1; k < end; k++ {
+			if s := slope(sd[start], sd[k], pressure[start], saturation[k]); s < nextSlope {
+				next, nextSlope = k, s
+			}
+		}
+
+		value := ceiling(next)
+		span := sd[next] - sd[start]
+		for k := start + 1; k <= next; k++ {
+			if span <= 0 {
+				pressure[k] = value
+				continue
+			}
+			pressure[k] = pressure[start] + (value-pressure[start])*(sd[k]-sd[start])/span
+		}
+
+		start = next
+	}
+
+	return pressure
+}
+
+func slope(x0, x1, y0, y1 float64) float64 {
+	if x1 <= x0 {
+		if y1 < y0 {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	}
+
+	return (y1 - y0) / (x1 - x0)
+}
+
+// vapourFlux is the diffusion flux in kg/(m²·s) between two planes
+func vapourFlux(sd0, sd1, p0, p1 float64) float64 {
+	if sd1-sd0 <= 1e-9 {
+		return 0
+	}
+
+	return vapourPermeabilityOfAir * (p0 - p1) / (sd1 - sd0)
+}
+
+// AnalyseCondensation runs the Glaser method of ISO 13788 on a calculated
+// build-up. The profile is taken at the design conditions, the monthly
+// balance uses the outdoor climate of the location and the design indoor
+// conditions all year round.
+func AnalyseCondensation(result models.InsulationResult, conditions models.DesignConditions, location models.ClimateLocation) models.CondensationAnalysis {
+	analysis := models.CondensationAnalysis{
+		Conditions: conditions,
+		Location:   location.Name,
+		Profile:    []models.VapourPoint{},
+		Months:     []models.CondensationMonth{},
+		DriesOut:   true,
+	}
+
+	planes := glaserPlanes(result)
+	if len(planes) < 2 || result.TotalResistance <= 0 {
+		return analysis
+	}
+	dry := make([]bool, len(planes))
+
+	design := solveGlaser(planes, result.TotalResistance,
+		conditions.IndoorTemperature, conditions.IndoorHumidity,
+		conditions.OutdoorTemperature, conditions.OutdoorHumidity, dry)
+	for k, plane := range planes {
+		analysis.Profile = append(analysis.Profile, models.VapourPoint{
+			Position:            plane.position,
+			EquivalentThickness: plane.sd,
+			Temperature:         design.temperature[k],
+			SaturationPressure:  design.saturationPressure[k],
+			VapourPressure:      design.vapourPressure[k],
+			Condensation:        design.rates[k] > 0,
+		})
+	}
+
+	if len(location.Temperature) != 12 || len(location.Humidity) != 12 {
+		return analysis
+	}
+
+	month := func(m int, wet []bool) glaserState {
+		return solveGlaser(planes, result.TotalResistance,
+			conditions.IndoorTemperature, conditions.IndoorHumidity,
+			location.Temperature[m], location.Humidity[m]/100, wet)
+	}
+
+	// Start with the first month that condenses after one that does not
+	condenses := make([]bool, 12)
+	for m := range condenses {
+		for _, rate := range month(m, dry).rates {
+			if rate > 0 {
+				condenses[m] = true
+			}
+		}
+	}
+	first := 0
+	for m := range condenses {
+		if condenses[m] && !condenses[(m+11)%12] {
+			first = m
+			break
+		}
+	}
+
+	months := make([]models.CondensationMonth, 12)
+	accumulated := make([]float64, len(planes))
+	for i := 0; i < 12; i++ {
+		m := (first + i) % 12
+
+		wet := make([]bool, len(planes))
+		before := 0.0
+		for k, amount := range accumulated {
+			wet[k] = amount > 0
+			before += amount
+		}
+
+		state := month(m, wet)
+		after := 0.0
+		for k, rate := range state.rates {
+			accumulated[k] = math.Max(0, accumulated[k]+rate*daysInMonth[m]*24*3600)
+			after += accumulated[k]
+		}
+
+		months[m] = models.CondensationMonth{
+			Month:              m + 1,
+			OutdoorTemperature: location.Temperature[m],
+			OutdoorHumidity:    location.Humidity[m] / 100,
+			Rate:               after - before,
+			Accumulation:       after,
+		}
+		analysis.MaxAccumulation = math.Max(analysis.MaxAccumulation, after)
+	}
+
+	analysis.Months = months
+	for _, amount := range accumulated {
+		if amount > 0 {
+			analysis.DriesOut = false
+		}
+	}
+
+	return analysis
+}
@@ -0,0 +1,70 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+func TestSaturationPressure(t *testing.T) {
+	tests := []struct {
+		temperature float64 // °C
+		pressure    float64 // Pa, ISO 13788 equations (E.7) and (E.8)
+	}{
+		{20, 2336.951},
+		{10, 1227.310},
+		{0, 610.5},
+		{-10, 259.333},
+	}
+
+	for _, tt := range tests {
+		assertClose(t, "psat", SaturationPressure(tt.temperature), tt.pressure, 1e-3)
+		assertClose(t, "θ(psat)", SaturationTemperature(SaturationPressure(tt.temperature)), tt.temperature, 1e-9)
+	}
+}
+
+// vapourLayer is a layer of thickness mm with a vapour resistance factor
+func vapourLayer(name string, thickness, lambda, mu float64) models.InsulationLayer {
+	return models.InsulationLayer{
+		Material:  models.Material{Name: name, Lambda: lambda, Mu: mu},
+		Thickness: thickness,
+	}
+}
+
+func TestAnalyseCondensation(t *testing.T) {
+	conditions := models.DesignConditions{IndoorTemperature: 20, IndoorHumidity: 0.5, OutdoorTemperature: -10, OutdoorHumidity: 0.8}
+	tests := []struct {
+		name     string
+		layers   []models.InsulationLayer
+		condense bool
+	}{
+		{
+			name: "vapour-tight membrane on the cold side",
+			layers: []models.InsulationLayer{
+				vapourLayer("Mineral wool", 200, 0.04, 1),
+				vapourLayer("Bitumen membrane", 5, 0.2, 50000),
+			},
+			condense: true,
+		},
+		{
+			name: "vapour barrier on the warm side",
+			layers: []models.InsulationLayer{
+				vapourLayer("Vapour barrier", 2, 0.4, 100000),
+				vapourLayer("Mineral wool", 200, 0.04, 1),
+				vapourLayer("Wood fibre board", 20, 0.05, 5),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(models.InsulationResult{BaseLayers: tt.layers, HeatFlow: models.HeatFlowHorizontal})
+			analysis := AnalyseCondensation(result, conditions, models.ClimateLocation{Name: "Design"})
+			if got := analysis.HasCondensation(); got != tt.condense {
+				t.Errorf("condensation = %v, want %v", got, tt.condense)
+			}
+			// The interior vapour pressure is φi · psat(θi)
+			assertClose(t, "pi", analysis.Profile[0].VapourPressure, 0.5*2336.951, 1e-2)
+		})
+	}
+}
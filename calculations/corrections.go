@@ -0,0 +1,67 @@
+package calculations
+
+import (
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// Fixings conducting less than this need no correction, ISO 6946 Annex F.2
+const minFixingConductivity = 1.0
+
+// Fixings that run all the way through the insulation have α = 0.8
+const fixingAlpha = 0.8
+
+// CorrectionTerms returns the ISO 6946 Annex F corrections to the U-value
+//
+//	ΔUf = α·λf·Af·nf / d0 · (R1/RT)²,  α = 0.8·d1/d0 for recessed fixings
+//	ΔUr = p·f·x · (R1/RT)²
+//
+// with R1 and d0 the resistance and thickness in mm of the insulation the
+// fixings run through or the rain runs past, and RT the total resistance
+// without corrections.
+func CorrectionTerms(fixings *models.Fixings, roof *models.InvertedRoof, insulationResistance, insulationThickness, totalResistance float64) models.UCorrections {
+	corrections := models.UCorrections{}
+	if totalResistance <= 0 || insulationResistance <= 0 || insulationThickness <= 0 {
+		return corrections
+	}
+
+	share := insulationResistance / totalResistance
+	share *= share
+
+	if fixings != nil && fixings.Conductivity >= minFixingConductivity {
+		corrections.Alpha = fixingAlpha * math.Min(fixings.Penetration/insulationThickness, 1)
+		corrections.Fixings = corrections.Alpha * fixings.Conductivity * fixings.CrossSection() * fixings.Count /
+			(insulationThickness / 1000) * share
+	}
+
+	if roof != nil {
+		corrections.Precipitation = roof.Precipitation * roof.Drainage.Factor() * share
+	}
+
+	return corrections
+}
+
+// insulationOf returns the resistance and the thickness in mm of the
+// insulation the corrections refer to: every added layer, as the optimizer
+// only adds insulation, and the base layers of insulation materials. Air
+// layers and layers outside a well-ventilated one never count.
+func insulationOf(base, added []models.InsulationLayer) (float64, float64) {
+	resistance, thickness := 0.0, 0.0
+	count := func(layer models.InsulationLayer) {
+		if layer.Air == nil && !layer.Excluded {
+			resistance += layer.Resistance
+			thickness += layer.Thickness
+		}
+	}
+	for _, layer := range base {
+		if layer.Material.Type == models.MaterialInsulation {
+			count(layer)
+		}
+	}
+	for _, layer := range added {
+		count(layer)
+	}
+
+	return resistance, thickness
+}
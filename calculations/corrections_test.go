@@ -0,0 +1,129 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+func TestCorrectionTerms(t *testing.T) {
+	// 150 mm of insulation λ 0.035 in a wall with RT = 4.655714 m²K/W,
+	// (R1/RT)² = 0.847419, six steel fixings of 4.5 mm per m²
+	insulation, thickness, total := 0.15/0.035, 150.0, 4.655714
+	steel := &models.Fixings{Count: 6, Diameter: 4.5, Conductivity: 50, Penetration: 150}
+	tests := []struct {
+		name                string
+		fixings             *models.Fixings
+		roof                *models.InvertedRoof
+		insulation          float64
+		alpha, fixing, rain float64
+	}{
+		{
+			// 0.8 · 50 · 1.590e-5 · 6 / 0.15 · 0.847419
+			name:       "fixings through the insulation",
+			fixings:    steel,
+			insulation: insulation,
+			alpha:      0.8,
+			fixing:     0.021563,
+		},
+		{
+			name:       "recessed fixings, α = 0.8 · d1/d0",
+			fixings:    &models.Fixings{Count: 6, Diameter: 4.5, Conductivity: 50, Penetration: 75},
+			insulation: insulation,
+			alpha:      0.4,
+			fixing:     0.010781,
+		},
+		{
+			name:       "fixings longer than the insulation keep α = 0.8",
+			fixings:    &models.Fixings{Count: 6, Diameter: 4.5, Conductivity: 50, Penetration: 200},
+			insulation: insulation,
+			alpha:      0.8,
+			fixing:     0.021563,
+		},
+		{
+			name:       "plastic fixings need no correction",
+			fixings:    &models.Fixings{Count: 6, Diameter: 8, Conductivity: 0.3, Penetration: 150},
+			insulation: insulation,
+		},
+		{
+			// 2 mm/day · 0.04 · 0.847419
+			name:       "inverted roof with open covering",
+			roof:       &models.InvertedRoof{Precipitation: 2, Drainage: models.DrainageOpen},
+			insulation: insulation,
+			rain:       0.067790,
+		},
+		{
+			// 2 mm/day · 0.03 · 0.847419
+			name:       "inverted roof with closed covering",
+			roof:       &models.InvertedRoof{Precipitation: 2, Drainage: models.DrainageClosed},
+			insulation: insulation,
+			rain:       0.050842,
+		},
+		{
+			name:       "fixings and rain together",
+			fixings:    steel,
+			roof:       &models.InvertedRoof{Precipitation: 2, Drainage: models.DrainageOpen},
+			insulation: insulation,
+			alpha:      0.8,
+			fixing:     0.021563,
+			rain:       0.067790,
+		},
+		{
+			name:    "no insulation to correct",
+			fixings: steel,
+			roof:    &models.InvertedRoof{Precipitation: 2, Drainage: models.DrainageOpen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrections := CorrectionTerms(tt.fixings, tt.roof, tt.insulation, thickness, total)
+			assertClose(t, "α", corrections.Alpha, tt.alpha, 1e-9)
+			assertClose(t, "ΔUf", corrections.Fixings, tt.fixing, 1e-6)
+			assertClose(t, "ΔUr", corrections.Precipitation, tt.rain, 1e-6)
+			assertClose(t, "ΔU", corrections.Total(), tt.fixing+tt.rain, 1e-6)
+		})
+	}
+}
+
+func TestEvaluateCorrections(t *testing.T) {
+	// 200 mm of concrete with 150 mm of EPS, RT = 0.13 + 0.1 + 4.285714 + 0.04
+	// = 4.555714 m²K/W, (R1/RT)² = 0.884980. Only the insulation counts as R1,
+	// whether it is part of the construction or added to it.
+	concrete := layer("Concrete", 200, 2.0)
+	concrete.Material.Type = models.MaterialWall
+	eps := layer("EPS", 150, 0.035)
+	steel := &models.Fixings{Count: 6, Diameter: 4.5, Conductivity: 50, Penetration: 150}
+	tests := []struct {
+		name   string
+		result models.InsulationResult
+		fixing float64
+	}{
+		{
+			name:   "insulation in the construction",
+			result: models.InsulationResult{BaseLayers: []models.InsulationLayer{concrete, eps}, Fixings: steel},
+			fixing: 0.022520,
+		},
+		{
+			name:   "insulation added to the construction",
+			result: models.InsulationResult{BaseLayers: []models.InsulationLayer{concrete}, Layers: []models.InsulationLayer{eps}, Fixings: steel},
+			fixing: 0.022520,
+		},
+		{
+			name:   "no insulation",
+			result: models.InsulationResult{BaseLayers: []models.InsulationLayer{concrete}, Fixings: steel},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.result.HeatFlow = models.HeatFlowHorizontal
+			result := Evaluate(tt.result)
+			if result.Corrections == nil {
+				t.Fatal("fixings need corrections")
+			}
+			assertClose(t, "ΔUf", result.Corrections.Fixings, tt.fixing, 1e-6)
+			assertClose(t, "U", result.TotalUValue, 1/result.TotalResistance+tt.fixing, 1e-6)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package calculations
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// transferMatrix relates the temperature and heat flow amplitudes on the two
+// sides of a layer, ISO 13786 clause 6
+type transferMatrix [2][2]complex128
+
+func (a transferMatrix) times(b transferMatrix) transferMatrix {
+	return transferMatrix{
+		{a[0][0]*b[0][0] + a[0][1]*b[1][0], a[0][0]*b[0][1] + a[0][1]*b[1][1]},
+		{a[1][0]*b[0][0] + a[1][1]*b[1][0], a[1][0]*b[0][1] + a[1][1]*b[1][1]},
+	}
+}
+
+// resistanceMatrix is the transfer matrix of a surface, an air layer or a
+// layer without heat capacity
+func resistanceMatrix(resistance float64) transferMatrix {
+	return transferMatrix{{1, complex(-resistance, 0)}, {0, 1}}
+}
+
+// layerMatrix is the transfer matrix of a homogeneous layer of thickness d
+// in m. With the periodic penetration depth δ = √(λT/πρc) and ξ = d/δ:
+//
+//	Z11 = Z22 = cosh ξ cos ξ + i sinh ξ sin ξ
+//	Z12 = -δ/2λ (sinh ξ cos ξ + cosh ξ sin ξ + i (cosh ξ sin ξ - sinh ξ cos ξ))
+//	Z21 = -λ/δ (sinh ξ cos ξ - cosh ξ sin ξ + i (sinh ξ cos ξ + cosh ξ sin ξ))
+func layerMatrix(d, lambda, heatCapacity, period float64) transferMatrix {
+	delta := math.Sqrt(lambda * period / (math.Pi * heatCapacity))
+	xi := d / delta
+	sh, ch := math.Sinh(xi), math.Cosh(xi)
+	s, c := math.Sin(xi), math.Cos(xi)
+
+	diagonal := complex(ch*c, sh*s)
+	return transferMatrix{
+		{diagonal, complex(-delta/(2*lambda), 0) * complex(sh*c+ch*s, ch*s-sh*c)},
+		{complex(-lambda/delta, 0) * complex(sh*c-ch*s, sh*c+ch*s), diagonal},
+	}
+}
+
+// DynamicProperties computes the ISO 13786 periodic thermal transmittance,
+// decrement factor, time shift and areal heat capacities of a build-up for a
+// 24 h cycle. The transfer matrix runs from the interior (side 1) to the
+// exterior (side 2),
+//
+//	Z = Zse · ZN ··· Z1 · Zsi,
+//
+// with Y12 = -1/Z12, f = |Y12|/U, Δt = -T/2π · arg(Y12) and
+// κ = T/2π · |(Z11 - 1)/Z12| on the interior side, Z22 on the exterior side.
+// Layers carry the resistance they have in the steady state, so bridged
+// layers use their share of the combined method with an area weighted ρc.
+// Air layers and layers without a density or specific heat are resistances.
+func DynamicProperties(result models.InsulationResult) models.DynamicProperties {
+	period := models.DynamicPeriod * 3600
+	properties := models.DynamicProperties{}
+
+	z := resistanceMatrix(result.InternalResistance)
+	resistance := result.InternalResistance + result.ExternalResistance
+	for _, layer := range result.AllLayers() {
+		if layer.Excluded {
+			continue
+		}
+		resistance += layer.Resistance
+
+		d := layer.Thickness / 1000
+		heat := layer.HeatCapacity()
+		if layer.Air != nil || d <= 0 || layer.Resistance <= 0 || heat <= 0 {
+			if layer.Air == nil && d > 0 {
+				properties.Massless = append(properties.Massless, layer.Material.Name)
+			}
+			z = resistanceMatrix(layer.Resistance).times(z)
+			continue
+		}
+		z = layerMatrix(d, d/layer.Resistance, heat, period).times(z)
+	}
+	z = resistanceMatrix(result.ExternalResistance).times(z)
+
+	y12 := -1 / z[0][1]
+	properties.PeriodicTransmittance = cmplx.Abs(y12)
+	properties.DecrementFactor = properties.PeriodicTransmittance * resistance
+
+	shift := -cmplx.Phase(y12) * models.DynamicPeriod / (2 * math.Pi)
+	if shift < 0 {
+		shift += models.DynamicPeriod
+	}
+	properties.TimeShift = shift
+
+	properties.InternalHeatCapacity = period / (2 * math.Pi) * cmplx.Abs((z[0][0]-1)/z[0][1]) / 1000
+	properties.ExternalHeatCapacity = period / (2 * math.Pi) * cmplx.Abs((z[1][1]-1)/z[0][1]) / 1000
+
+	return properties
+}
@@ -0,0 +1,78 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// massiveLayer is a layer of thickness mm with a heat capacity
+func massiveLayer(name string, thickness, lambda, density, specificHeat float64) models.InsulationLayer {
+	return models.InsulationLayer{
+		Material:  models.Material{Name: name, Lambda: lambda, Density: density, SpecificHeat: specificHeat},
+		Thickness: thickness,
+	}
+}
+
+func TestDynamicProperties(t *testing.T) {
+	// Reference values from the ISO 13786 transfer matrices, evaluated by
+	// hand for a 24 h period with Rsi = 0.13 and Rse = 0.04
+	tests := []struct {
+		name          string
+		layers        []models.InsulationLayer
+		transmittance float64 // |Y12|, W/m²K
+		decrement     float64
+		shift         float64 // h
+		internal      float64 // κ1, kJ/m²K
+		external      float64 // κ2, kJ/m²K
+	}{
+		{
+			name:          "200 mm concrete",
+			layers:        []models.InsulationLayer{massiveLayer("Concrete", 200, 2.0, 2400, 1000)},
+			transmittance: 1.953100,
+			decrement:     0.527337,
+			shift:         5.4751,
+			internal:      86.420,
+			external:      175.878,
+		},
+		{
+			name: "concrete insulated outside",
+			layers: []models.InsulationLayer{
+				massiveLayer("Concrete", 200, 2.0, 2400, 1000),
+				massiveLayer("EPS", 100, 0.04, 30, 1030),
+			},
+			transmittance: 0.0629893,
+			decrement:     0.174480,
+			shift:         7.6577,
+			internal:      83.796,
+			external:      6.0607,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(models.InsulationResult{BaseLayers: tt.layers, HeatFlow: models.HeatFlowHorizontal})
+			properties := DynamicProperties(result)
+			assertClose(t, "|Y12|", properties.PeriodicTransmittance, tt.transmittance, 1e-6)
+			assertClose(t, "f", properties.DecrementFactor, tt.decrement, 1e-6)
+			assertClose(t, "Δt", properties.TimeShift, tt.shift, 1e-4)
+			assertClose(t, "κ1", properties.InternalHeatCapacity, tt.internal, 1e-3)
+			assertClose(t, "κ2", properties.ExternalHeatCapacity, tt.external, 1e-3)
+		})
+	}
+}
+
+func TestDynamicPropertiesMassless(t *testing.T) {
+	// Without heat capacity the build-up is a pure resistance, |Y12| = U
+	result := Evaluate(models.InsulationResult{
+		BaseLayers: []models.InsulationLayer{layer("Mineral wool", 200, 0.04)},
+		HeatFlow:   models.HeatFlowHorizontal,
+	})
+	properties := DynamicProperties(result)
+
+	assertClose(t, "|Y12|", properties.PeriodicTransmittance, result.TotalUValue, 1e-9)
+	assertClose(t, "f", properties.DecrementFactor, 1, 1e-9)
+	if len(properties.Massless) != 1 || properties.Massless[0] != "Mineral wool" {
+		t.Errorf("massless layers = %v, want [Mineral wool]", properties.Massless)
+	}
+}
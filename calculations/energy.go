@@ -0,0 +1,132 @@
+package calculations
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+const (
+	// frameShading is the correction for non-perpendicular incidence on
+	// glazing, F_W of ISO 13790
+	frameShading = 0.9
+	// defaultGlazingFactor is the glazed share of a window without a
+	// product of the library
+	defaultGlazingFactor = 0.7
+	// defaultGValue is the solar transmittance of double glazing for windows
+	// without a product of the library
+	defaultGValue = 0.5
+	// referenceTimeConstant is τ0 of the monthly method, h
+	referenceTimeConstant = 15.0
+)
+
+// HeatingDemand is the monthly quasi-steady-state heating demand in the
+// spirit of ISO 13790 and ISO 52016-1. Each month the transmission and
+// ventilation losses at the mean outdoor temperature are offset by the
+// internal and solar gains, weighted by the utilisation factor
+//
+//	η = (1 − γ^a) / (1 − γ^(a+1)),  γ = Q_gn / Q_ht,  a = 1 + τ/15 h
+//
+// where τ = C_m / H is the time constant of the building. H_T comes from
+// BuildingHeatLoss, so ground floors lose heat to the mean outdoor
+// temperature as well. Solar gains come through the windows facing their
+// orientation, a horizontal surface for windows without one.
+func HeatingDemand(building models.Building, location models.ClimateLocation) (models.EnergyDemand, error) {
+	if building.Energy == nil {
+		return models.EnergyDemand{}, errors.New("the building has no energy inputs")
+	}
+	inputs := *building.Energy
+	if inputs.FloorArea <= 0 {
+		return models.EnergyDemand{}, errors.New("the heated floor area must be positive")
+	}
+	if len(location.Temperature) != 12 {
+		return models.EnergyDemand{}, fmt.Errorf("climate location %q needs 12 monthly temperatures", location.Name)
+	}
+
+	demand := models.EnergyDemand{
+		Inputs: inputs,
+		HT:     BuildingHeatLoss(building).HT,
+		HV:     0.34 * inputs.AirChangeRate * inputs.Volume * (1 - inputs.HeatRecovery),
+		Months: make([]models.MonthlyEnergy, 12),
+	}
+
+	// solar aperture A·F_gl·F_W·g per orientation, m²
+	apertures := map[string]float64{}
+	for _, element := range building.Elements {
+		if element.Type != models.ElementWindow {
+			continue
+		}
+		glazing, gValue := defaultGlazingFactor, defaultGValue
+		if element.Window != nil {
+			glazing = WindowU(element.Window.Product).GlazingFactor
+			gValue = element.Window.Product.GValue
+		}
+		apertures[element.Orientation] += element.Area * glazing * frameShading * gValue
+	}
+
+	irradiation := map[string][]float64{}
+	for orientation := range apertures {
+		values, ok := location.SolarIrradiation(orientation)
+		if !ok {
+			return models.EnergyDemand{}, fmt.Errorf("climate location %q has no irradiation data facing %s", location.Name, orientationName(orientation))
+		}
+		irradiation[orientation] = values
+	}
+
+	h := demand.HT + demand.HV
+	if h > 0 {
+		demand.TimeConstant = inputs.HeatCapacity * inputs.FloorArea / 3.6 / h
+	}
+	a := 1 + demand.TimeConstant/referenceTimeConstant
+
+	for i := range demand.Months {
+		hours := daysInMonth[i] * 24
+		month := models.MonthlyEnergy{
+			Month:              i + 1,
+			OutdoorTemperature: location.Temperature[i],
+		}
+
+		difference := building.IndoorTemperature - month.OutdoorTemperature
+		month.Transmission = demand.HT * difference * hours / 1000
+		month.Ventilation = demand.HV * difference * hours / 1000
+		month.InternalGains = inputs.InternalGains * inputs.FloorArea * hours / 1000
+		for orientation, aperture := range apertures {
+			month.SolarGains += aperture * irradiation[orientation][i]
+		}
+
+		month.Utilisation = gainUtilisation(month.Gains(), month.Losses(), a)
+		month.Demand = math.Max(0, month.Losses()-month.Utilisation*month.Gains())
+
+		demand.Months[i] = month
+		demand.Annual += month.Demand
+	}
+	demand.PerFloorArea = demand.Annual / inputs.FloorArea
+
+	return demand, nil
+}
+
+// gainUtilisation is the share of the gains that offsets the losses of a
+// month for a building with the numerical parameter a
+func gainUtilisation(gains, losses, a float64) float64 {
+	if gains <= 0 {
+		return 1
+	}
+	if losses <= 0 {
+		return 0
+	}
+
+	gamma := gains / losses
+	if math.Abs(gamma-1) < 1e-9 {
+		return a / (a + 1)
+	}
+	return (1 - math.Pow(gamma, a)) / (1 - math.Pow(gamma, a+1))
+}
+
+func orientationName(orientation string) string {
+	if orientation == "" {
+		return models.HorizontalIrradiation
+	}
+	return orientation
+}
@@ -0,0 +1,118 @@
+package calculations
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// referenceBuilding has 100 m² of wall at U 0.2 and a 2 m² south window at
+// U 1.0 without a product, on 100 m² of floor
+func referenceBuilding() models.Building {
+	return models.Building{
+		IndoorTemperature:  20,
+		OutdoorTemperature: -20,
+		Energy: &models.EnergyInputs{
+			FloorArea:     100,
+			Volume:        250,
+			AirChangeRate: 0.5,
+			InternalGains: 3,
+			HeatCapacity:  165,
+		},
+		Elements: []models.BuildingElement{
+			{Name: "Wall", Type: models.ElementWall, Area: 100, UValue: 0.2},
+			{Name: "Window", Type: models.ElementWindow, Orientation: "S", Area: 2, UValue: 1.0},
+		},
+	}
+}
+
+func referenceLocation() models.ClimateLocation {
+	south := make([]float64, 12)
+	for i := range south {
+		south[i] = 50
+	}
+	return models.ClimateLocation{
+		Name:        "Reference",
+		Temperature: []float64{-2, -1, 3, 8, 13, 16, 18, 18, 13, 8, 3, -1},
+		Humidity:    []float64{85, 85, 80, 75, 70, 70, 70, 75, 80, 85, 85, 85},
+		Irradiation: map[string][]float64{"S": south},
+	}
+}
+
+func TestHeatingDemand(t *testing.T) {
+	// H_T = 22 W/K, H_V = 0.34 · 0.5 · 250 = 42.5 W/K,
+	// τ = 165 · 100 / 3.6 / 64.5 = 71.06 h, a = 1 + τ/15 = 5.7373,
+	// solar aperture 2 · 0.7 · 0.9 · 0.5 = 0.63 m²
+	demand, err := HeatingDemand(referenceBuilding(), referenceLocation())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertClose(t, "H_T", demand.HT, 22, 1e-9)
+	assertClose(t, "H_V", demand.HV, 42.5, 1e-9)
+	assertClose(t, "τ", demand.TimeConstant, 71.0594, 1e-4)
+
+	// January: Q_ht = 64.5 · 22 · 744 / 1000, Q_gn = 223.2 + 0.63 · 50
+	january := demand.Months[0]
+	assertClose(t, "January losses", january.Losses(), 1055.736, 1e-6)
+	assertClose(t, "January gains", january.Gains(), 254.7, 1e-6)
+	assertClose(t, "January η", january.Utilisation, 0.999783, 1e-6)
+	assertClose(t, "January demand", january.Demand, 801.0914, 1e-4)
+
+	assertClose(t, "annual demand", demand.Annual, 4167.763, 1e-3)
+	assertClose(t, "per floor area", demand.PerFloorArea, 41.67763, 1e-5)
+}
+
+func TestHeatingDemandErrors(t *testing.T) {
+	withoutEnergy := referenceBuilding()
+	withoutEnergy.Energy = nil
+
+	withoutArea := referenceBuilding()
+	withoutArea.Energy.FloorArea = 0
+
+	shortClimate := referenceLocation()
+	shortClimate.Temperature = shortClimate.Temperature[:6]
+
+	noIrradiation := referenceLocation()
+	noIrradiation.Irradiation = nil
+
+	tests := []struct {
+		name     string
+		building models.Building
+		location models.ClimateLocation
+	}{
+		{"no energy inputs", withoutEnergy, referenceLocation()},
+		{"no floor area", withoutArea, referenceLocation()},
+		{"six months of climate", referenceBuilding(), shortClimate},
+		{"no irradiation facing the window", referenceBuilding(), noIrradiation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := HeatingDemand(tt.building, tt.location); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+}
+
+func TestGainUtilisation(t *testing.T) {
+	a := 5.0
+	tests := []struct {
+		name          string
+		gains, losses float64
+		utilisation   float64
+	}{
+		{"no gains", 0, 100, 1},
+		{"no losses", 100, 0, 0},
+		{"gains equal to the losses", 100, 100, a / (a + 1)},
+		{"half the losses", 50, 100, (1 - math.Pow(0.5, a)) / (1 - math.Pow(0.5, a+1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertClose(t, "η", gainUtilisation(tt.gains, tt.losses, a), tt.utilisation, 1e-12)
+		})
+	}
+}
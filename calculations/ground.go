@@ -0,0 +1,58 @@
+package calculations
+
+import (
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// GroundFloorUValue returns the U-value of a slab-on-ground floor per
+// ISO 13370 with floorResistance the thermal resistance of the floor
+// build-up without surface resistances. Edge insulation lowers it by
+// 2·ΔΨ/B'.
+func GroundFloorUValue(ground models.GroundFloor, floorResistance float64) models.GroundFloorResult {
+	lambda := ground.Soil.Conductivity()
+	result := models.GroundFloorResult{
+		CharacteristicDimension: ground.CharacteristicDimension(),
+		EquivalentThickness:     ground.WallThickness/1000 + lambda*(rsiDownward+floorResistance+rse),
+	}
+
+	b, dt := result.CharacteristicDimension, result.EquivalentThickness
+	if b <= 0 || dt <= 0 {
+		return result
+	}
+
+	// Uninsulated and moderately insulated floors, then well insulated ones
+	if dt < b {
+		result.BasicUValue = 2 * lambda / (math.Pi*b + dt) * math.Log(math.Pi*b/dt+1)
+	} else {
+		result.BasicUValue = lambda / (0.457*b + dt)
+	}
+
+	result.EdgePsi = edgeInsulationPsi(ground, lambda, dt)
+	result.UValue = result.BasicUValue + 2*result.EdgePsi/b
+
+	return result
+}
+
+// edgeInsulationPsi returns ΔΨ of horizontal or vertical edge insulation,
+// ISO 13370 equations (10) and (11)
+func edgeInsulationPsi(ground models.GroundFloor, lambda, dt float64) float64 {
+	if ground.EdgeInsulation == models.EdgeInsulationNone || ground.EdgeInsulationWidth <= 0 {
+		return 0
+	}
+
+	// The additional thickness the insulation adds over the soil it replaces
+	thickness := ground.EdgeInsulationThickness / 1000
+	extra := (LayerResistance(ground.EdgeInsulationThickness, ground.EdgeInsulationLambda) - thickness/lambda) * lambda
+	if extra <= 0 {
+		return 0
+	}
+
+	d := ground.EdgeInsulationWidth
+	if ground.EdgeInsulation == models.EdgeInsulationVertical {
+		d *= 2
+	}
+
+	return -lambda / math.Pi * (math.Log(d/dt+1) - math.Log(d/(dt+extra)+1))
+}
@@ -0,0 +1,84 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+func TestGroundFloorUValue(t *testing.T) {
+	// Clay, λ = 1.5 W/mK, dt = w + λ (0.17 + Rf + 0.04)
+	tests := []struct {
+		name       string
+		ground     models.GroundFloor
+		resistance float64
+		dt, psi, u float64
+	}{
+		{
+			// B' = 5 m, dt = 0.3 + 1.5 · 0.21 = 0.615 m < B'
+			name:       "uninsulated floor",
+			ground:     models.GroundFloor{Area: 100, Perimeter: 40, WallThickness: 300, Soil: models.SoilClay},
+			resistance: 0,
+			dt:         0.615,
+			u:          0.602594,
+		},
+		{
+			// dt = 0.3 + 1.5 · 5.21 = 8.115 m ≥ B', U = λ / (0.457 B' + dt)
+			name:       "well insulated floor",
+			ground:     models.GroundFloor{Area: 100, Perimeter: 40, WallThickness: 300, Soil: models.SoilClay},
+			resistance: 5,
+			dt:         8.115,
+			u:          0.144231,
+		},
+		{
+			// B' = 13.333 m, dt = 0.4 + 1.5 · 0.71 = 1.465 m
+			name:       "large floor",
+			ground:     models.GroundFloor{Area: 200, Perimeter: 30, WallThickness: 400, Soil: models.SoilClay},
+			resistance: 0.5,
+			dt:         1.465,
+			u:          0.234415,
+		},
+		{
+			// d' = 1.5 (0.05/0.035 - 0.05/1.5) = 2.092857 m, D = 1 m
+			name: "horizontal edge insulation",
+			ground: models.GroundFloor{
+				Area: 100, Perimeter: 40, WallThickness: 300, Soil: models.SoilClay,
+				EdgeInsulation: models.EdgeInsulationHorizontal, EdgeInsulationWidth: 1,
+				EdgeInsulationThickness: 50, EdgeInsulationLambda: 0.035,
+			},
+			resistance: 0.5,
+			dt:         1.365,
+			psi:        -0.141141,
+			u:          0.387463,
+		},
+		{
+			// Vertical insulation counts twice its depth, 2D = 2 m
+			name: "vertical edge insulation",
+			ground: models.GroundFloor{
+				Area: 100, Perimeter: 40, WallThickness: 300, Soil: models.SoilClay,
+				EdgeInsulation: models.EdgeInsulationVertical, EdgeInsulationWidth: 1,
+				EdgeInsulationThickness: 50, EdgeInsulationLambda: 0.035,
+			},
+			resistance: 0.5,
+			dt:         1.365,
+			psi:        -0.212886,
+			u:          0.358766,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GroundFloorUValue(tt.ground, tt.resistance)
+			assertClose(t, "dt", result.EquivalentThickness, tt.dt, 1e-9)
+			assertClose(t, "ΔΨ", result.EdgePsi, tt.psi, 1e-6)
+			assertClose(t, "U", result.UValue, tt.u, 1e-6)
+		})
+	}
+}
+
+func TestGroundFloorUValueWithoutPerimeter(t *testing.T) {
+	result := GroundFloorUValue(models.GroundFloor{Area: 100, WallThickness: 300, Soil: models.SoilClay}, 1)
+	if result.UValue != 0 {
+		t.Errorf("U = %f without an exposed perimeter, want 0", result.UValue)
+	}
+}
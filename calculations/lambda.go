@@ -0,0 +1,41 @@
+package calculations
+
+import (
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// Declared lambdas refer to a mean temperature of 10 °C and the dry state
+const declaredTemperature = 10.0
+
+// ConversionFactors returns the ISO 10456 clause 8 conversion factors of a
+// material for the design conditions
+//
+//	FT = e^(fT·(T - 10 °C))
+//	Fm = e^(fψ·ψ)
+//
+// and the ageing factor Fa, 1 when the material has none
+func ConversionFactors(material models.Material, conditions models.LambdaConditions) (float64, float64, float64) {
+	temperature := math.Exp(material.TemperatureCoefficient * (conditions.Temperature - declaredTemperature))
+	moisture := math.Exp(material.MoistureCoefficient * material.MoistureContent)
+
+	ageing := material.AgeingFactor
+	if ageing <= 0 {
+		ageing = 1
+	}
+
+	return temperature, moisture, ageing
+}
+
+// DesignLambda returns the design thermal conductivity λ = λdeclared·FT·Fm·Fa,
+// or the declared lambda when conditions is nil
+func DesignLambda(material models.Material, conditions *models.LambdaConditions) float64 {
+	if conditions == nil {
+		return material.Lambda
+	}
+
+	temperature, moisture, ageing := ConversionFactors(material, *conditions)
+
+	return material.Lambda * temperature * moisture * ageing
+}
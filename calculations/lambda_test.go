@@ -0,0 +1,34 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+func TestDesignLambda(t *testing.T) {
+	material := models.Material{
+		Lambda:                 0.035,
+		TemperatureCoefficient: 0.003,
+		MoistureCoefficient:    4,
+		MoistureContent:        0.01,
+		AgeingFactor:           1.05,
+	}
+	tests := []struct {
+		name       string
+		material   models.Material
+		conditions *models.LambdaConditions
+		lambda     float64
+	}{
+		{"declared without conditions", material, nil, 0.035},
+		{"declared at 10 °C and dry", models.Material{Lambda: 0.035, TemperatureCoefficient: 0.003}, &models.LambdaConditions{Temperature: 10}, 0.035},
+		// 0.035 · e^(0.003 · 20) · e^(4 · 0.01) · 1.05
+		{"all conversion factors", material, &models.LambdaConditions{Temperature: 30}, 0.0406150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertClose(t, "λ", DesignLambda(tt.material, tt.conditions), tt.lambda, 1e-7)
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package calculations
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// DefaultThicknesses are the layer thicknesses in mm tried for materials
+// without a board catalogue, e.g. blown or sprayed insulation
+var DefaultThicknesses = []float64{20, 40, 60, 80, 100, 120, 140, 160, 180, 200, 220, 240, 260, 280, 300}
+
+// At most this many boards of one product are stacked to make up a layer
+const maxBoardsPerLayer = 2
+
+// OptimizerOptions configures Optimize
+type OptimizerOptions struct {
+	TargetUValue float64
+	MaxLayers    int     // at most this many different materials are stacked
+	MaxThickness float64 // limit on the added thickness in mm, 0 for none
+	Objective    models.Objective
+	CarbonPrice  float64          // per kgCO2e, weighs carbon against cost
+	MinFireClass models.FireClass // materials of a worse Euroclass are left out, empty for any
+	MinTimeLag   float64          // h, ISO 13786 time shift build-ups must reach, 0 for none
+}
+
+// layerOption is one way to build a layer of a material
+type layerOption struct {
+	layer      models.InsulationLayer
+	resistance float64
+	cost       float64
+	carbon     float64
+}
+
+// candidate is a build-up reduced to the objectives the front is built on
+type candidate struct {
+	options   []layerOption
+	score     float64
+	uValue    float64
+	thickness float64
+}
+
+// Optimize enumerates every combination of up to MaxLayers of the given
+// materials, each made of the boards it is sold in, added on the exterior
+// side of the construction. It keeps the Pareto-optimal ones for the
+// objective (cost, embodied carbon or both), U-value and added thickness.
+// Build-ups thicker than MaxThickness are never considered. The
+// construction without insulation is a candidate too, so the front is never
+// empty even when nothing fits. Materials below MinFireClass are excluded
+// before the search. With MinTimeLag, build-ups with a shorter ISO 13786
+// time shift are dropped too, which needs a full evaluation of each of them.
+// The time shift depends on the order of the layers, so a selection whose
+// materials fall short in the given order is tried in every other order
+// before it is dropped. Candidates counts the build-ups that pass.
+func Optimize(construction models.Construction, materials []models.Material, options OptimizerOptions) models.OptimizationResult {
+	if options.MaxLayers < 1 {
+		options.MaxLayers = 1
+	}
+
+	excluded := []string{}
+	compliant := make([]models.Material, 0, len(materials))
+	for _, material := range materials {
+		if material.FireClass.Meets(options.MinFireClass) {
+			compliant = append(compliant, material)
+		} else {
+			excluded = append(excluded, material.Name)
+		}
+	}
+	materials = compliant
+
+	base := construction.Result()
+	base.Currency, _ = models.CommonCurrency(materials)
+	base = Evaluate(base)
+
+	perMaterial := make([][]layerOption, len(materials))
+	for i, material := range materials {
+		perMaterial[i] = layerOptions(material, options.MaxThickness, construction.Lambda)
+	}
+
+	front := []candidate{}
+	count := 1
+	front = addToFront(front, candidate{uValue: base.TotalUValue})
+
+	forEachCombination(len(materials), options.MaxLayers, func(selection []int) {
+		counts := make([]int, len(selection))
+		for i, m := range selection {
+			counts[i] = len(perMaterial[m])
+		}
+
+		picked := make([]layerOption, len(selection))
+		forEachChoice(counts, func(choice []int) {
+			cost, carbon := 0.0, 0.0
+			next := candidate{}
+			for i, m := range selection {
+				option := perMaterial[m][choice[i]]
+				picked[i] = option
+				cost += option.cost
+				carbon += option.carbon
+				next.thickness += option.layer.Thickness
+			}
+			if exceedsThickness(next.thickness, options.MaxThickness) {
+				return
+			}
+			if options.MinTimeLag > 0 && !orderForTimeLag(base, picked, options.MinTimeLag) {
+				return
+			}
+			next.score = options.Objective.Score(cost, carbon, options.CarbonPrice)
+			next.uValue = uValueOf(base, picked)
+			next.options = picked
+
+			count++
+			front = addToFront(front, next)
+		})
+	})
+
+	result := models.OptimizationResult{
+		TargetUValue: options.TargetUValue,
+		MaxThickness: options.MaxThickness,
+		Objective:    options.Objective,
+		CarbonPrice:  options.CarbonPrice,
+		Currency:     base.Currency,
+		MinFireClass: options.MinFireClass,
+		MinTimeLag:   options.MinTimeLag,
+		Excluded:     excluded,
+		Front:        make([]models.InsulationResult, 0, len(front)),
+		Best:         -1,
+		Candidates:   count,
+	}
+
+	for _, solution := range front {
+		result.Front = append(result.Front, Evaluate(build(base, solution.options)))
+	}
+
+	sort.SliceStable(result.Front, func(i, j int) bool {
+		si, sj := result.Score(result.Front[i]), result.Score(result.Front[j])
+		if si != sj {
+			return si < sj
+		}
+		return result.Front[i].TotalUValue < result.Front[j].TotalUValue
+	})
+
+	// The front is sorted by score, so the first one meeting the target wins
+	for i, solution := range result.Front {
+		if solution.TotalUValue <= options.TargetUValue && meetsTimeLag(solution, options.MinTimeLag) {
+			result.Best = i
+			break
+		}
+	}
+
+	return result
+}
+
+// build adds the layers of the picked options to the base construction
+func build(base models.InsulationResult, options []layerOption) models.InsulationResult {
+	result := base
+	result.Layers = make([]models.InsulationLayer, len(options))
+	for i, option := range options {
+		result.Layers[i] = option.layer
+	}
+
+	return result
+}
+
+// meetsTimeLag reports whether a build-up delays the daily heat wave by at
+// least minimum hours
+func meetsTimeLag(result models.InsulationResult, minimum float64) bool {
+	if minimum <= 0 {
+		return true
+	}
+
+	return DynamicProperties(Evaluate(result)).TimeShift >= minimum
+}
+
+// orderForTimeLag puts the picked layers in the first order, starting with
+// the given one, in which the build-up delays the daily heat wave by at least
+// minimum hours. It reports false when no order does.
+func orderForTimeLag(base models.InsulationResult, picked []layerOption, minimum float64) bool {
+	found := false
+	forEachPermutation(len(picked), func(order []int) bool {
+		ordered := make([]layerOption, len(picked))
+		for i, j := range order {
+			ordered[i] = picked[j]
+		}
+		if meetsTimeLag(build(base, ordered), minimum) {
+			copy(picked, ordered)
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
+
+// uValueOf returns the U-value of the base construction with the picked
+// homogeneous insulation layers added. Outside a well-ventilated air layer
+// they do not count. Outside a slightly ventilated one the cap on the outer
+// resistance depends on every layer, so those build-ups are evaluated in
+// full.
+func uValueOf(base models.InsulationResult, picked []layerOption) float64 {
+	added, addedThickness := 0.0, 0.0
+	for _, option := range picked {
+		added += option.resistance
+		addedThickness += option.layer.Thickness
+	}
+
+	for _, layer := range base.BaseLayers {
+		if layer.Air != nil && layer.Air.Ventilation == models.SlightlyVentilated {
+			return Evaluate(build(base, picked)).TotalUValue
+		}
+		if layer.Ventilated() {
+			added, addedThickness = 0, 0
+		}
+	}
+
+	resistance := base.TotalResistance + added
+	if base.Bounds != nil {
+		resistance = withAddedResistance(*base.Bounds, added).Total()
+	}
+
+	if base.Ground != nil {
+		return GroundFloorUValue(*base.Ground, resistance-base.InternalResistance-base.ExternalResistance).UValue
+	}
+
+	uValue := 1 / resistance
+	if base.Corrections != nil {
+		insulation, thickness := insulationOf(base.BaseLayers, nil)
+		uValue += CorrectionTerms(base.Fixings, base.InvertedRoof, insulation+added, thickness+addedThickness, resistance).Total()
+	}
+
+	return uValue
+}
+
+// layerOptions returns the layers that can be built from a material: every
+// stack of up to maxBoardsPerLayer of its boards, or the default thicknesses
+// when it has no board catalogue. Each total thickness is kept once, made of
+// as few boards as possible. Layers thicker than maxThickness are left out.
+func layerOptions(material models.Material, maxThickness float64, conditions *models.LambdaConditions) []layerOption {
+	lambda := DesignLambda(material, conditions)
+	options := []layerOption{}
+	add := func(thickness float64, boards []float64) {
+		if exceedsThickness(thickness, maxThickness) {
+			return
+		}
+		options = append(options, layerOption{
+			layer:      models.InsulationLayer{Material: material, Thickness: thickness, Boards: boards},
+			resistance: LayerResistance(thickness, lambda),
+			cost:       LayerCost(material, thickness),
+			carbon:     LayerCarbon(material, thickness),
+		})
+	}
+
+	boards := []float64{}
+	for _, board := range material.Thicknesses {
+		if board > 0 {
+			boards = append(boards, board)
+		}
+	}
+	sort.Float64s(boards)
+
+	if len(boards) == 0 {
+		for _, thickness := range DefaultThicknesses {
+			add(thickness, nil)
+		}
+		return options
+	}
+
+	// Walk by number of boards so a total is claimed by the shortest stack
+	seen := map[float64]bool{}
+	for size := 1; size <= maxBoardsPerLayer; size++ {
+		forEachStack(boards, size, func(stack []float64) {
+			total := 0.0
+			for _, board := range stack {
+				total += board
+			}
+
+			key := math.Round(total * 10)
+			if !seen[key] {
+				seen[key] = true
+				add(total, append([]float64{}, stack...))
+			}
+		})
+	}
+
+	return options
+}
+
+// exceedsThickness reports whether thickness is over the limit, 0 meaning no
+// limit
+func exceedsThickness(thickness, limit float64) bool {
+	return limit > 0 && thickness > limit+objectiveTolerance
+}
+
+// addToFront adds c to the Pareto front unless a member dominates it or
+// equals it on every objective, and drops the members c dominates
+func addToFront(front []candidate, c candidate) []candidate {
+	for _, member := range front {
+		if member.dominates(c) || member.equals(c) {
+			return front
+		}
+	}
+
+	kept := front[:0]
+	for _, member := range front {
+		if !c.dominates(member) {
+			kept = append(kept, member)
+		}
+	}
+
+	c.options = append([]layerOption{}, c.options...)
+
+	return append(kept, c)
+}
+
+const objectiveTolerance = 1e-9
+
+// dominates reports whether a is at least as good as b on the objective,
+// U-value and added thickness, and better on at least one of them
+func (a candidate) dominates(b candidate) bool {
+	if a.score > b.score+objectiveTolerance || a.uValue > b.uValue+objectiveTolerance || a.thickness > b.thickness+objectiveTolerance {
+		return false
+	}
+
+	return a.score < b.score-objectiveTolerance || a.uValue < b.uValue-objectiveTolerance || a.thickness < b.thickness-objectiveTolerance
+}
+
+func (a candidate) equals(b candidate) bool {
+	return math.Abs(a.score-b.score) <= objectiveTolerance &&
+		math.Abs(a.uValue-b.uValue) <= objectiveTolerance &&
+		math.Abs(a.thickness-b.thickness) <= objectiveTolerance
+}
+
+// forEachCombination calls fn with every selection of 1 to k distinct
+// indexes out of n, in increasing order
+func forEachCombination(n, k int, fn func([]int)) {
+	var walk func(start int, selection []int)
+	walk = func(start int, selection []int) {
+		if len(selection) > 0 {
+			fn(selection)
+		}
+		if len(selection) == k {
+			return
+		}
+		for i := start; i < n; i++ {
+			walk(i+1, append(selection, i))
+		}
+	}
+
+	walk(0, []int{})
+}
+
+// forEachChoice calls fn with every way of picking one of counts[i] options
+// for each position i
+func forEachChoice(counts []int, fn func([]int)) {
+	for _, count := range counts {
+		if count == 0 {
+			return
+		}
+	}
+
+	choice := make([]int, len(counts))
+	for {
+		fn(choice)
+
+		i := 0
+		for ; i < len(counts); i++ {
+			choice[i]++
+			if choice[i] < counts[i] {
+				break
+			}
+			choice[i] = 0
+		}
+		if i == len(counts) {
+			return
+		}
+	}
+}
+
+// forEachPermutation calls fn with every order of the indexes 0 to n-1,
+// starting with the increasing one, until fn returns false
+func forEachPermutation(n int, fn func([]int) bool) {
+	order := make([]int, 0, n)
+	used := make([]bool, n)
+	var walk func() bool
+	walk = func() bool {
+		if len(order) == n {
+			return fn(order)
+		}
+		for i := 0; i < n; i++ {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			order = append(order, i)
+			next := walk()
+			order = order[:len(order)-1]
+			used[i] = false
+			if !next {
+				return false
+			}
+		}
+		return true
+	}
+
+	walk()
+}
+
+// forEachStack calls fn with every multiset of size boards, thickest first
+func forEachStack(boards []float64, size int, fn func([]float64)) {
+	var walk func(start int, stack []float64)
+	walk = func(start int, stack []float64) {
+		if len(stack) == size {
+			fn(stack)
+			return
+		}
+		for i := start; i >= 0; i-- {
+			walk(i, append(stack, boards[i]))
+		}
+	}
+
+	walk(len(boards)-1, []float64{})
+}
@@ -0,0 +1,234 @@
+package calculations
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// board is an insulation material sold in boards of the given thicknesses,
+// priced per m³
+func board(name string, lambda, price float64, thicknesses ...float64) models.Material {
+	return models.Material{
+		Name: name, Lambda: lambda, Price: price, PriceBasis: models.PricePerCubicMetre,
+		Thicknesses: thicknesses, Type: models.MaterialInsulation,
+	}
+}
+
+// concreteWall is 200 mm of concrete, RT = 0.27 m²K/W
+func concreteWall() models.Construction {
+	return models.Construction{
+		Layers:   []models.InsulationLayer{{Material: models.Material{Name: "Concrete", Lambda: 2.0, Type: models.MaterialWall}, Thickness: 200}},
+		HeatFlow: models.HeatFlowHorizontal,
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	materials := []models.Material{
+		board("EPS", 0.038, 60, 50, 100),
+		board("PIR", 0.022, 200, 50, 100),
+		board("Mineral wool", 0.035, 50, 100, 150),
+	}
+	result := Optimize(concreteWall(), materials, OptimizerOptions{TargetUValue: 0.2, MaxLayers: 2})
+
+	if len(result.Front) < 2 {
+		t.Fatalf("got %d solutions on the front, want several", len(result.Front))
+	}
+	for i, a := range result.Front {
+		for j, b := range result.Front {
+			if i != j && a.TotalCost <= b.TotalCost && a.TotalUValue <= b.TotalUValue && a.AddedThickness() <= b.AddedThickness() &&
+				(a.TotalCost < b.TotalCost || a.TotalUValue < b.TotalUValue || a.AddedThickness() < b.AddedThickness()) {
+				t.Errorf("solution %d dominates solution %d on the front", i, j)
+			}
+		}
+		if i > 0 && result.Front[i-1].TotalCost > a.TotalCost {
+			t.Errorf("the front is not sorted by cost at %d", i)
+		}
+	}
+
+	if len(result.Front[0].Layers) != 0 {
+		t.Errorf("the cheapest solution adds %d layers, want the construction as it is", len(result.Front[0].Layers))
+	}
+	if result.Best < 0 {
+		t.Fatal("the target is within reach")
+	}
+	best := result.Front[result.Best]
+	if best.TotalUValue > 0.2 {
+		t.Errorf("best U = %.4f, over the target", best.TotalUValue)
+	}
+	for _, solution := range result.Front[:result.Best] {
+		if solution.TotalUValue <= 0.2 {
+			t.Errorf("a cheaper solution at %.2f meets the target than the best at %.2f", solution.TotalCost, best.TotalCost)
+		}
+	}
+}
+
+func TestOptimizeMaxThickness(t *testing.T) {
+	materials := []models.Material{board("EPS", 0.038, 60, 50, 100), board("Mineral wool", 0.035, 50, 100, 150)}
+	result := Optimize(concreteWall(), materials, OptimizerOptions{TargetUValue: 0.1, MaxLayers: 2, MaxThickness: 150})
+
+	for _, solution := range result.Front {
+		if thickness := solution.AddedThickness(); thickness > 150 {
+			t.Errorf("a %.0f mm build-up is over the 150 mm limit", thickness)
+		}
+	}
+	// 150 mm of mineral wool gives U = 1 / (0.27 + 0.15/0.035) = 0.219
+	if !result.Infeasible() {
+		t.Error("U 0.1 cannot be reached within 150 mm")
+	}
+	assertClose(t, "recommended U", result.Recommended().TotalUValue, 1/(0.27+0.15/0.035), 1e-9)
+}
+
+func TestOptimizeCarbon(t *testing.T) {
+	// Same insulation, one with a fifth of the carbon at twice the price
+	eps := board("EPS", 0.035, 60, 100)
+	eps.GWP, eps.GWPBasis = 100, models.GWPPerCubicMetre
+	fibre := board("Wood fibre", 0.035, 120, 100)
+	fibre.GWP, fibre.GWPBasis = 20, models.GWPPerCubicMetre
+	materials := []models.Material{eps, fibre}
+
+	best := func(objective models.Objective, carbonPrice float64) string {
+		result := Optimize(concreteWall(), materials, OptimizerOptions{TargetUValue: 0.5, MaxLayers: 1, Objective: objective, CarbonPrice: carbonPrice})
+		if result.Best < 0 {
+			t.Fatalf("%s: the target is within reach", objective)
+		}
+		return result.Front[result.Best].Layers[0].Material.Name
+	}
+
+	if got := best(models.ObjectiveCost, 0); got != "EPS" {
+		t.Errorf("cheapest = %s, want EPS", got)
+	}
+	if got := best(models.ObjectiveCarbon, 0); got != "Wood fibre" {
+		t.Errorf("lowest carbon = %s, want Wood fibre", got)
+	}
+	// 6 + 10 · 0.5 = 11 against 12 + 2 · 0.5 = 13, and 6 + 10 · 1 = 16 against 14
+	if got := best(models.ObjectiveWeighted, 0.5); got != "EPS" {
+		t.Errorf("weighted at 0.5 per kg = %s, want EPS", got)
+	}
+	if got := best(models.ObjectiveWeighted, 1); got != "Wood fibre" {
+		t.Errorf("weighted at 1 per kg = %s, want Wood fibre", got)
+	}
+}
+
+func TestForEachCombination(t *testing.T) {
+	selections := [][]int{}
+	forEachCombination(3, 2, func(selection []int) {
+		selections = append(selections, append([]int{}, selection...))
+	})
+	want := [][]int{{0}, {0, 1}, {0, 2}, {1}, {1, 2}, {2}}
+	if !reflect.DeepEqual(selections, want) {
+		t.Errorf("selections = %v, want %v", selections, want)
+	}
+}
+
+func TestOptimizeFireClass(t *testing.T) {
+	eps := board("EPS", 0.038, 60, 100)
+	eps.FireClass = models.FireClassE
+	wool := board("Mineral wool", 0.035, 50, 100)
+	wool.FireClass = models.FireClassA1
+	unclassified := board("Cork", 0.04, 100, 100)
+
+	result := Optimize(concreteWall(), []models.Material{eps, wool, unclassified}, OptimizerOptions{TargetUValue: 0.5, MaxLayers: 2, MinFireClass: models.FireClassB})
+
+	if !reflect.DeepEqual(result.Excluded, []string{"EPS", "Cork"}) {
+		t.Errorf("excluded = %v, want [EPS Cork]", result.Excluded)
+	}
+	for _, solution := range result.Front {
+		for _, layer := range solution.Layers {
+			if layer.Material.Name != "Mineral wool" {
+				t.Errorf("%s is below the minimum class", layer.Material.Name)
+			}
+		}
+	}
+	// The construction and one or two boards of mineral wool
+	if result.Candidates != 3 {
+		t.Errorf("candidates = %d, want 3", result.Candidates)
+	}
+}
+
+func TestOptimizeTimeLag(t *testing.T) {
+	eps := board("EPS", 0.035, 60, 100)
+	eps.Density, eps.SpecificHeat = 20, 1450
+	concrete := models.Material{Name: "Concrete", Lambda: 2.0, Density: 2400, SpecificHeat: 1000, Thicknesses: []float64{150}, Type: models.MaterialWall}
+	plastered := models.Construction{
+		Layers:   []models.InsulationLayer{{Material: models.Material{Name: "Plaster", Lambda: 0.7, Density: 1400, SpecificHeat: 1000}, Thickness: 15}},
+		HeatFlow: models.HeatFlowHorizontal,
+	}
+
+	// Concrete inside the insulation shifts the heat wave by 6.32 h, outside
+	// it by 6.21 h, and neither layer alone reaches 6.26 h
+	result := Optimize(plastered, []models.Material{eps, concrete}, OptimizerOptions{TargetUValue: 1, MaxLayers: 2, MaxThickness: 250, MinTimeLag: 6.26})
+
+	if result.Candidates != 2 {
+		t.Errorf("candidates = %d, want the construction and one build-up", result.Candidates)
+	}
+	if result.Best < 0 {
+		t.Fatal("the concrete can go inside the insulation")
+	}
+	layers := result.Front[result.Best].Layers
+	if len(layers) != 2 || layers[0].Material.Name != "Concrete" || layers[1].Material.Name != "EPS" {
+		t.Errorf("layers = %v, want the concrete inside the EPS", layers)
+	}
+	if shift := DynamicProperties(result.Front[result.Best]).TimeShift; shift < 6.26 {
+		t.Errorf("time shift = %.2f h, under the minimum", shift)
+	}
+}
+
+func TestLayerOptions(t *testing.T) {
+	thicknesses := func(options []layerOption) []float64 {
+		totals := []float64{}
+		for _, option := range options {
+			totals = append(totals, option.layer.Thickness)
+		}
+		return totals
+	}
+
+	eps := board("EPS", 0.038, 60, 100, 50, 20)
+	options := layerOptions(eps, 0, nil)
+	// Every total of one or two boards, thickest first, 100 coming from a
+	// single board rather than 50 + 50
+	if want := []float64{100, 50, 20, 200, 150, 120, 70, 40}; !reflect.DeepEqual(thicknesses(options), want) {
+		t.Errorf("thicknesses = %v, want %v", thicknesses(options), want)
+	}
+	for _, option := range options {
+		if len(option.layer.Boards) > maxBoardsPerLayer {
+			t.Errorf("%v stacks more than %d boards", option.layer.Boards, maxBoardsPerLayer)
+		}
+		if option.layer.Thickness == 100 && len(option.layer.Boards) != 1 {
+			t.Errorf("100 mm is made of %v, want one board", option.layer.Boards)
+		}
+	}
+	assertClose(t, "cost of 100 mm", options[0].cost, 6, 1e-9)
+	assertClose(t, "R of 100 mm", options[0].resistance, 0.1/0.038, 1e-9)
+
+	if got := thicknesses(layerOptions(eps, 100, nil)); !reflect.DeepEqual(got, []float64{100, 50, 20, 70, 40}) {
+		t.Errorf("thicknesses within 100 mm = %v", got)
+	}
+
+	blown := board("Cellulose", 0.04, 55)
+	if got := thicknesses(layerOptions(blown, 0, nil)); !reflect.DeepEqual(got, DefaultThicknesses) {
+		t.Errorf("without boards = %v, want the default thicknesses", got)
+	}
+}
+
+func TestForEachPermutation(t *testing.T) {
+	orders := [][]int{}
+	forEachPermutation(3, func(order []int) bool {
+		orders = append(orders, append([]int{}, order...))
+		return true
+	})
+	want := [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	if !reflect.DeepEqual(orders, want) {
+		t.Errorf("orders = %v, want %v", orders, want)
+	}
+
+	calls := 0
+	forEachPermutation(3, func([]int) bool {
+		calls++
+		return calls < 2
+	})
+	if calls != 2 {
+		t.Errorf("got %d calls after stopping, want 2", calls)
+	}
+}
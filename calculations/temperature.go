@@ -0,0 +1,89 @@
+package calculations
+
+import (
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// Interior surface resistance for the mould and surface condensation
+// assessment, ISO 13788 clause 5
+const rsiMould = 0.25
+
+// Mould can grow once the surface relative humidity stays above 80 %
+const mouldSurfaceHumidity = 0.8
+
+// temperatureAt returns the steady-state temperature at a plane that lies
+// behind the given thermal resistance, measured from the indoor air
+func temperatureAt(resistance, totalResistance, indoorTemperature, outdoorTemperature float64) float64 {
+	return indoorTemperature - (indoorTemperature-outdoorTemperature)*resistance/totalResistance
+}
+
+// SaturationTemperature is the inverse of SaturationPressure: the
+// temperature in °C at which the given vapour pressure in Pa saturates
+func SaturationTemperature(pressure float64) float64 {
+	x := math.Log(pressure / 610.5)
+	if pressure >= 610.5 {
+		return 237.3 * x / (17.269 - x)
+	}
+
+	return 265.5 * x / (21.875 - x)
+}
+
+// effectiveResistance is the resistance between the indoor and the outdoor
+// air. For floors on the ground it includes the soil, taken from the
+// ISO 13370 U-value.
+func effectiveResistance(result models.InsulationResult) float64 {
+	if result.Ground != nil && result.TotalUValue > 0 {
+		return 1 / result.TotalUValue
+	}
+
+	return result.TotalResistance
+}
+
+// TemperatureProfile computes the temperature at every layer boundary and
+// the interior surface temperature factor
+//
+//	fRsi = (θsi - θe) / (θi - θe)
+//
+// with Rsi = 0.25 m²K/W, compared with the minimum factor that keeps the
+// surface below 80 % relative humidity at the indoor conditions.
+func TemperatureProfile(result models.InsulationResult, conditions models.DesignConditions) models.TemperatureProfile {
+	profile := models.TemperatureProfile{
+		Conditions: conditions,
+		Boundaries: []models.TemperaturePoint{},
+	}
+	if result.TotalResistance <= 0 {
+		return profile
+	}
+
+	indoor, outdoor := conditions.IndoorTemperature, conditions.OutdoorTemperature
+	total := effectiveResistance(result)
+
+	position := 0.0
+	resistance := result.InternalResistance
+	profile.Boundaries = append(profile.Boundaries, models.TemperaturePoint{
+		Position:    position,
+		Temperature: temperatureAt(resistance, total, indoor, outdoor),
+	})
+	for _, layer := range result.AllLayers() {
+		position += layer.Thickness
+		resistance += layer.Resistance
+		profile.Boundaries = append(profile.Boundaries, models.TemperaturePoint{
+			Position:    position,
+			Temperature: temperatureAt(resistance, total, indoor, outdoor),
+		})
+	}
+
+	mouldResistance := total - result.InternalResistance + rsiMould
+	profile.FRsi = 1 - rsiMould/mouldResistance
+
+	if indoor > outdoor && conditions.IndoorHumidity > 0 {
+		indoorPressure := conditions.IndoorHumidity * SaturationPressure(indoor)
+		minSurfaceTemperature := SaturationTemperature(indoorPressure / mouldSurfaceHumidity)
+		profile.FRsiMin = (minSurfaceTemperature - outdoor) / (indoor - outdoor)
+		profile.MouldRisk = profile.FRsi < profile.FRsiMin
+	}
+
+	return profile
+}
@@ -0,0 +1,308 @@
+package calculations
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+const (
+	// bridgeTolerance is the relative residual the conjugate gradients stop at
+	bridgeTolerance = 1e-10
+	// bridgeMaxIterations bounds the conjugate gradients
+	bridgeMaxIterations = 50000
+)
+
+// bridgeLink joins a material cell to a neighbouring material cell or to an
+// environment with a conductance per metre of junction, W/mK
+type bridgeLink struct {
+	To          int // unknown at the other side, -1 for an environment
+	Environment int
+	Conductance float64
+	Row, Column int // the cell at the other side
+}
+
+// rasterise draws the regions of a geometry onto its grid. Cells hold the
+// index of their material, -1 for voids and -2-i for environment i.
+func rasterise(geometry models.BridgeGeometry, library []models.Material) ([][]int, []models.Material, error) {
+	byName := make(map[string]models.Material, len(library))
+	for _, material := range library {
+		byName[material.Name] = material
+	}
+
+	columns, rows := geometry.Columns(), geometry.Rows()
+	cells := make([][]int, rows)
+	for r := range cells {
+		cells[r] = make([]int, columns)
+		for c := range cells[r] {
+			cells[r][c] = -1
+		}
+	}
+
+	var materials []models.Material
+	indices := map[string]int{}
+	for _, region := range geometry.Regions {
+		value := 0
+		if region.Environment != "" {
+			environment, _ := geometry.Environment(region.Environment)
+			value = -2 - environment
+		} else {
+			index, ok := indices[region.Material]
+			if !ok {
+				material, found := byName[region.Material]
+				if !found {
+					return nil, nil, fmt.Errorf("material %q is not in the library", region.Material)
+				}
+				if material.Lambda <= 0 {
+					return nil, nil, fmt.Errorf("material %q needs a positive lambda", region.Material)
+				}
+				index = len(materials)
+				indices[region.Material] = index
+				materials = append(materials, material)
+			}
+			value = index
+		}
+
+		// A cell belongs to the region holding its centre
+		for r := 0; r < rows; r++ {
+			y := (float64(r) + 0.5) * geometry.Cell
+			if y < region.Y || y >= region.Y+region.Height {
+				continue
+			}
+			for c := 0; c < columns; c++ {
+				x := (float64(c) + 0.5) * geometry.Cell
+				if x >= region.X && x < region.X+region.Width {
+					cells[r][c] = value
+				}
+			}
+		}
+	}
+
+	return cells, materials, nil
+}
+
+// SolveThermalBridge computes the steady-state temperature field of a 2D
+// junction with the finite volume method. Every material cell is a node; two
+// cells of conductivities λ1 and λ2 are joined by 2λ1λ2/(λ1+λ2) and a cell
+// facing an environment by d/(d/2λ + Rs) for a cell of edge d. The symmetric
+// system is solved by conjugate gradients with a Jacobi preconditioner.
+//
+// The heat flow Φ leaving the warm environments gives L2D = Φ/ΔT, and
+// ψ = L2D - Σ U·l over the flanking elements, as in ISO 10211.
+func SolveThermalBridge(geometry models.BridgeGeometry, library []models.Material) (models.ThermalBridgeResult, error) {
+	result := models.ThermalBridgeResult{Geometry: geometry}
+	if err := geometry.Validate(); err != nil {
+		return result, err
+	}
+
+	cells, materials, err := rasterise(geometry, library)
+	if err != nil {
+		return result, err
+	}
+	for _, material := range materials {
+		result.Materials = append(result.Materials, material.Name)
+	}
+
+	rows, columns := len(cells), len(cells[0])
+	d := geometry.Cell / 1000
+
+	// Number the material cells
+	unknown := make([][]int, rows)
+	var positions [][2]int
+	for r := range cells {
+		unknown[r] = make([]int, columns)
+		for c, value := range cells[r] {
+			unknown[r][c] = -1
+			if value >= 0 {
+				unknown[r][c] = len(positions)
+				positions = append(positions, [2]int{r, c})
+			}
+		}
+	}
+	if len(positions) == 0 {
+		return result, fmt.Errorf("the geometry has no material cells")
+	}
+
+	links := make([][]bridgeLink, len(positions))
+	diagonal := make([]float64, len(positions))
+	rhs := make([]float64, len(positions))
+	touches := false
+	for i, position := range positions {
+		r, c := position[0], position[1]
+		lambda := materials[cells[r][c]].Lambda
+		for _, step := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nr, nc := r+step[0], c+step[1]
+			if nr < 0 || nr >= rows || nc < 0 || nc >= columns || cells[nr][nc] == -1 {
+				continue
+			}
+			link := bridgeLink{To: -1, Environment: -1, Row: nr, Column: nc}
+			if neighbour := cells[nr][nc]; neighbour >= 0 {
+				other := materials[neighbour].Lambda
+				link.To = unknown[nr][nc]
+				link.Conductance = 2 * lambda * other / (lambda + other)
+			} else {
+				link.Environment = -2 - neighbour
+				environment := geometry.Environments[link.Environment]
+				link.Conductance = d / (d/(2*lambda) + environment.Resistance)
+				rhs[i] += link.Conductance * environment.Temperature
+				touches = true
+			}
+			diagonal[i] += link.Conductance
+			links[i] = append(links[i], link)
+		}
+	}
+	if !touches {
+		return result, fmt.Errorf("no material cell touches an environment")
+	}
+	if !connected(links) {
+		return result, fmt.Errorf("some material is cut off from every environment by voids")
+	}
+
+	temperatures, iterations, err := conjugateGradients(links, diagonal, rhs)
+	if err != nil {
+		return result, err
+	}
+	result.Iterations = iterations
+
+	result.Cells = cells
+	result.Temperatures = make([][]float64, rows)
+	for r := range result.Temperatures {
+		result.Temperatures[r] = make([]float64, columns)
+	}
+	for i, position := range positions {
+		result.Temperatures[position[0]][position[1]] = temperatures[i]
+	}
+
+	// Heat flow and surface temperatures over the faces of the warm side
+	warm, cold := geometry.Temperatures()
+	result.MinSurfaceTemperature = math.Inf(1)
+	for i, position := range positions {
+		for _, link := range links[i] {
+			if link.Environment < 0 {
+				continue
+			}
+			environment := geometry.Environments[link.Environment]
+			if environment.Temperature != warm {
+				continue
+			}
+			flow := link.Conductance * (environment.Temperature - temperatures[i])
+			result.HeatFlow += flow
+
+			surface := environment.Temperature - flow/d*environment.Resistance
+			if surface < result.MinSurfaceTemperature {
+				result.MinSurfaceTemperature = surface
+				// The middle of the face between the cell and the air
+				result.MinSurfaceX = (float64(position[1]+link.Column)/2 + 0.5) * geometry.Cell
+				result.MinSurfaceY = (float64(position[0]+link.Row)/2 + 0.5) * geometry.Cell
+			}
+		}
+	}
+	if math.IsInf(result.MinSurfaceTemperature, 1) {
+		return result, fmt.Errorf("no material cell touches the warm side")
+	}
+
+	result.Coupling = result.HeatFlow / (warm - cold)
+	for _, element := range geometry.Flanking {
+		result.FlankingLosses += element.UValue * element.Length / 1000
+	}
+	result.Psi = result.Coupling - result.FlankingLosses
+	result.FRsi = (result.MinSurfaceTemperature - cold) / (warm - cold)
+
+	return result, nil
+}
+
+// connected checks that heat can flow from every material cell to an
+// environment, without which the system would be singular
+func connected(links [][]bridgeLink) bool {
+	reached := make([]bool, len(links))
+	var queue []int
+	for i := range links {
+		for _, link := range links[i] {
+			if link.Environment >= 0 {
+				reached[i] = true
+				queue = append(queue, i)
+				break
+			}
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, link := range links[i] {
+			if link.To >= 0 && !reached[link.To] {
+				reached[link.To] = true
+				queue = append(queue, link.To)
+			}
+		}
+	}
+	for _, ok := range reached {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// conjugateGradients solves the symmetric positive definite system of the
+// cell links with a Jacobi preconditioner
+func conjugateGradients(links [][]bridgeLink, diagonal, rhs []float64) ([]float64, int, error) {
+	n := len(rhs)
+	multiply := func(x, into []float64) {
+		for i := range x {
+			value := diagonal[i] * x[i]
+			for _, link := range links[i] {
+				if link.To >= 0 {
+					value -= link.Conductance * x[link.To]
+				}
+			}
+			into[i] = value
+		}
+	}
+	dot := func(a, b []float64) float64 {
+		sum := 0.0
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+
+	x := make([]float64, n)
+	residual := make([]float64, n)
+	copy(residual, rhs)
+	z := make([]float64, n)
+	for i := range z {
+		z[i] = residual[i] / diagonal[i]
+	}
+	direction := make([]float64, n)
+	copy(direction, z)
+	product := make([]float64, n)
+
+	norm := math.Sqrt(dot(rhs, rhs))
+	if norm == 0 {
+		return x, 0, nil
+	}
+	rz := dot(residual, z)
+	for iteration := 1; iteration <= bridgeMaxIterations; iteration++ {
+		multiply(direction, product)
+		alpha := rz / dot(direction, product)
+		for i := range x {
+			x[i] += alpha * direction[i]
+			residual[i] -= alpha * product[i]
+		}
+		if math.Sqrt(dot(residual, residual)) <= bridgeTolerance*norm {
+			return x, iteration, nil
+		}
+		for i := range z {
+			z[i] = residual[i] / diagonal[i]
+		}
+		next := dot(residual, z)
+		beta := next / rz
+		rz = next
+		for i := range direction {
+			direction[i] = z[i] + beta*direction[i]
+		}
+	}
+
+	return nil, bridgeMaxIterations, fmt.Errorf("the solver did not converge in %d iterations", bridgeMaxIterations)
+}
@@ -0,0 +1,105 @@
+package calculations
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// slab is a plane wall of the given layers, interior on the left, drawn
+// 1000 mm high on a 10 mm grid with the exterior air on the right
+func slab(layers []models.InsulationLayer) models.BridgeGeometry {
+	geometry := models.BridgeGeometry{
+		Name: "Slab",
+		Cell: 10,
+		Environments: []models.BridgeEnvironment{
+			{Name: "Interior", Temperature: 20, Resistance: 0.13},
+			{Name: "Exterior", Temperature: -10, Resistance: 0.04},
+		},
+		Regions: []models.BridgeRegion{{Environment: "Interior", Width: 10, Height: 1000}},
+	}
+
+	x := 10.0
+	for _, layer := range layers {
+		geometry.Regions = append(geometry.Regions, models.BridgeRegion{Material: layer.Material.Name, X: x, Width: layer.Thickness, Height: 1000})
+		x += layer.Thickness
+	}
+	geometry.Regions = append(geometry.Regions, models.BridgeRegion{Environment: "Exterior", X: x, Width: 10, Height: 1000})
+	geometry.Width, geometry.Height = x+10, 1000
+
+	return geometry
+}
+
+func TestSolveThermalBridgeSlab(t *testing.T) {
+	// In one dimension the finite volume links are exact, so L2D over 1 m is
+	// the U-value and the surface follows θsi = θi - (θi - θe) Rsi / RT
+	tests := []struct {
+		name       string
+		layers     []models.InsulationLayer
+		resistance float64 // RT, m²K/W
+	}{
+		{"concrete", []models.InsulationLayer{layer("Concrete", 200, 1.0)}, 0.37},
+		{"insulated concrete", []models.InsulationLayer{layer("Concrete", 100, 1.0), layer("EPS", 100, 0.04)}, 2.77},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := make([]models.Material, 0, len(tt.layers))
+			for _, layer := range tt.layers {
+				library = append(library, layer.Material)
+			}
+			geometry := slab(tt.layers)
+			geometry.Flanking = []models.FlankingElement{{Name: "Wall", UValue: 1 / tt.resistance, Length: 1000}}
+
+			result, err := SolveThermalBridge(geometry, library)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertClose(t, "L2D", result.Coupling, 1/tt.resistance, 1e-6)
+			assertClose(t, "Φ", result.HeatFlow, 30/tt.resistance, 1e-5)
+			assertClose(t, "ψ", result.Psi, 0, 1e-6)
+			assertClose(t, "θsi", result.MinSurfaceTemperature, 20-30*0.13/tt.resistance, 1e-6)
+			assertClose(t, "fRsi", result.FRsi, 1-0.13/tt.resistance, 1e-8)
+		})
+	}
+}
+
+func TestSolveThermalBridgeErrors(t *testing.T) {
+	concrete := []models.Material{{Name: "Concrete", Lambda: 1.0}}
+	valid := slab([]models.InsulationLayer{layer("Concrete", 200, 1.0)})
+
+	unknown := slab([]models.InsulationLayer{layer("Brick", 200, 0.5)})
+
+	isolated := valid
+	isolated.Regions = valid.Regions[1:2]
+
+	oneTemperature := valid
+	oneTemperature.Environments = valid.Environments[:1]
+
+	// Columns · Rows of this grid overflows an int
+	overflowing := valid
+	overflowing.Width, overflowing.Height = 1e308, 1e308
+
+	notANumber := valid
+	notANumber.Cell = math.NaN()
+
+	tests := []struct {
+		name     string
+		geometry models.BridgeGeometry
+	}{
+		{"material missing from the library", unknown},
+		{"no environment touches the material", isolated},
+		{"a single environment", oneTemperature},
+		{"a grid too large to count", overflowing},
+		{"a cell size that is not a number", notANumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SolveThermalBridge(tt.geometry, concrete); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package calculations
+
+import (
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// Surface resistances in m²K/W, ISO 6946 Table 7
+const (
+	rsiUpward     = 0.10
+	rsiHorizontal = 0.13
+	rsiDownward   = 0.17
+	rse           = 0.04
+)
+
+// SurfaceResistances returns Rsi and Rse for the given heat-flow direction.
+// Anything unknown is treated as horizontal (walls).
+func SurfaceResistances(direction models.HeatFlowDirection) (float64, float64) {
+	switch direction {
+	case models.HeatFlowUpward:
+		return rsiUpward, rse
+	case models.HeatFlowDownward:
+		return rsiDownward, rse
+	default:
+		return rsiHorizontal, rse
+	}
+}
+
+// LayerResistance returns R = d/λ of a homogeneous layer with the thickness in mm
+func LayerResistance(thickness, lambda float64) float64 {
+	if lambda <= 0 {
+		return 0
+	}
+
+	return thickness / 1000 / lambda
+}
+
+// LayerCost returns the cost per m² of a new layer, thickness in mm, with the
+// price normalised from its basis to per m³.
+func LayerCost(material models.Material, thickness float64) float64 {
+	return thickness * material.PricePerCubicMetre() / 1000
+}
+
+// LayerCarbon returns the A1–A3 embodied carbon of a layer in kgCO2e/m²,
+// thickness in mm
+func LayerCarbon(material models.Material, thickness float64) float64 {
+	return thickness / 1000 * material.CarbonPerCubicMetre()
+}
+
+// Evaluate fills in the layer resistances, the surface resistances, the
+// total resistance, U = 1/R_total and the cost and embodied carbon of the
+// added layers. Slab-on-ground floors get their U-value from ISO 13370. Layers
+// in series add their resistances, never their conductances. Constructions
+// with a bridged layer use the combined method, see CombinedResistance.
+// Fixings and inverted roofs add the ΔU of CorrectionTerms. With
+// LambdaConditions set, layers use their ISO 10456 design lambda.
+func Evaluate(result models.InsulationResult) models.InsulationResult {
+	result.InternalResistance, result.ExternalResistance = SurfaceResistances(result.HeatFlow)
+
+	count := len(result.BaseLayers)
+	layers := evaluateLayers(result.AllLayers(), result.HeatFlow, result.LambdaConditions)
+	result.ExternalResistance = applyAirLayers(layers, result.InternalResistance, result.ExternalResistance)
+	result.BaseLayers, result.Layers = layers[:count:count], layers[count:]
+
+	total := result.InternalResistance + result.ExternalResistance
+	result.TotalCost = 0
+	result.TotalCarbon = 0
+	if result.Currency == "" {
+		result.Currency = models.DefaultCurrency
+	}
+	for i, layer := range layers {
+		total += layer.Resistance
+		if i >= count {
+			result.TotalCost += LayerCost(layer.Material, layer.Thickness)
+			if layer.Air == nil && layer.Material.Currency != "" {
+				result.Currency = layer.Material.Currency
+			}
+			result.TotalCarbon += layer.Carbon
+		}
+	}
+
+	result.Bounds = nil
+	bridgedResistance := 0.0
+	for _, layer := range layers {
+		if layer.Bridged() {
+			bridgedResistance += layer.Resistance
+		}
+	}
+	if bridgedResistance > 0 {
+		bounds := CombinedResistance(result.InternalResistance, result.ExternalResistance, layers, result.LambdaConditions)
+		result.Bounds = &bounds
+
+		// Layers carry their share of RT, so temperatures still add up
+		correction := bounds.Total() - total
+		spreadCorrection(layers, correction, bridgedResistance)
+		total = bounds.Total()
+	}
+
+	result.TotalResistance = total
+	result.TotalUValue = 1 / total
+
+	result.Corrections = nil
+	if result.Ground == nil && (result.Fixings != nil || result.InvertedRoof != nil) {
+		resistance, thickness := insulationOf(layers[:count], layers[count:])
+		corrections := CorrectionTerms(result.Fixings, result.InvertedRoof, resistance, thickness, total)
+		result.Corrections = &corrections
+		result.TotalUValue += corrections.Total()
+	}
+
+	// Floors on the ground lose heat through the soil as well
+	if result.Ground != nil {
+		ground := GroundFloorUValue(*result.Ground, total-result.InternalResistance-result.ExternalResistance)
+		result.GroundDetails = &ground
+		result.TotalUValue = ground.UValue
+	}
+
+	return result
+}
+
+func evaluateLayers(layers []models.InsulationLayer, heatFlow models.HeatFlowDirection, conditions *models.LambdaConditions) []models.InsulationLayer {
+	evaluated := make([]models.InsulationLayer, len(layers))
+	for i, layer := range layers {
+		layer.Excluded = false
+		if layer.Air != nil {
+			direction := layer.Air.HeatFlow
+			if direction == "" {
+				direction = heatFlow
+			}
+			layer.Resistance = AirLayerResistance(layer.Thickness, direction, layer.Air.Ventilation)
+			layer.Lambda = 0
+			layer.Carbon = 0
+			evaluated[i] = layer
+			continue
+		}
+
+		layer.Lambda = DesignLambda(layer.Material, conditions)
+		layer.Resistance = LayerResistance(layer.Thickness, BridgedLambda(layer, conditions))
+		layer.Carbon = LayerCarbon(layer.Material, layer.Thickness)
+		if layer.Bridged() {
+			fraction := layer.Bridge.Fraction
+			layer.Carbon = (1-fraction)*layer.Carbon + fraction*LayerCarbon(layer.Bridge.Material, layer.Thickness)
+		}
+		evaluated[i] = layer
+	}
+
+	return evaluated
+}
@@ -0,0 +1,162 @@
+package calculations
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// assertClose fails the test when got is further than tolerance from want
+func assertClose(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %.6f, want %.6f ± %g", name, got, want, tolerance)
+	}
+}
+
+// layer is a homogeneous layer of thickness mm and conductivity lambda
+func layer(name string, thickness, lambda float64) models.InsulationLayer {
+	return models.InsulationLayer{
+		Material:  models.Material{Name: name, Lambda: lambda, Type: models.MaterialInsulation},
+		Thickness: thickness,
+	}
+}
+
+// airLayer is an air layer of thickness mm
+func airLayer(thickness float64, ventilation models.VentilationClass) models.InsulationLayer {
+	return models.InsulationLayer{
+		Material:  models.Material{Name: "Air"},
+		Thickness: thickness,
+		Air:       &models.AirLayer{Ventilation: ventilation},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		heatFlow   models.HeatFlowDirection
+		layers     []models.InsulationLayer
+		resistance float64 // RT, m²K/W
+	}{
+		{
+			// 0.13 + 0.250/0.037 + 0.100/0.038 + 0.04
+			name:       "two insulation layers in a wall",
+			heatFlow:   models.HeatFlowHorizontal,
+			layers:     []models.InsulationLayer{layer("Mineral wool", 250, 0.037), layer("EPS", 100, 0.038)},
+			resistance: 9.558336,
+		},
+		{
+			// 0.10 + 0.200/0.04 + 0.04
+			name:       "roof, heat flow upward",
+			heatFlow:   models.HeatFlowUpward,
+			layers:     []models.InsulationLayer{layer("Mineral wool", 200, 0.04)},
+			resistance: 5.14,
+		},
+		{
+			// 0.17 + 0.100/0.035 + 0.04
+			name:       "floor, heat flow downward",
+			heatFlow:   models.HeatFlowDownward,
+			layers:     []models.InsulationLayer{layer("XPS", 100, 0.035)},
+			resistance: 3.067143,
+		},
+		{
+			// 0.13 + 2.5 + 0.18 (Table 8, 50 mm) + 0.2 + 0.04
+			name:       "unventilated cavity",
+			heatFlow:   models.HeatFlowHorizontal,
+			layers:     []models.InsulationLayer{layer("Mineral wool", 100, 0.04), airLayer(50, models.Unventilated), layer("Brick", 100, 0.5)},
+			resistance: 3.05,
+		},
+		{
+			// 0.13 + 2.5 + 0.09 + brick and Rse limited to 0.15, clause 6.9.3
+			name:       "slightly ventilated cavity",
+			heatFlow:   models.HeatFlowHorizontal,
+			layers:     []models.InsulationLayer{layer("Mineral wool", 100, 0.04), airLayer(50, models.SlightlyVentilated), layer("Brick", 100, 0.5)},
+			resistance: 2.87,
+		},
+		{
+			// 0.13 + 2.5 + Rse = Rsi, the cavity and the cladding left out, clause 6.9.4
+			name:       "well ventilated cavity",
+			heatFlow:   models.HeatFlowHorizontal,
+			layers:     []models.InsulationLayer{layer("Mineral wool", 100, 0.04), airLayer(50, models.WellVentilated), layer("Cladding", 20, 0.13)},
+			resistance: 2.76,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Evaluate(models.InsulationResult{BaseLayers: tt.layers, HeatFlow: tt.heatFlow})
+			assertClose(t, "RT", result.TotalResistance, tt.resistance, 1e-6)
+			assertClose(t, "U", result.TotalUValue, 1/tt.resistance, 1e-7)
+		})
+	}
+}
+
+func TestLayerResistance(t *testing.T) {
+	assertClose(t, "R", LayerResistance(100, 0.04), 2.5, 1e-12)
+	assertClose(t, "R without lambda", LayerResistance(100, 0), 0, 0)
+}
+
+func TestAirLayerResistance(t *testing.T) {
+	tests := []struct {
+		thickness   float64
+		direction   models.HeatFlowDirection
+		ventilation models.VentilationClass
+		resistance  float64
+	}{
+		{0, models.HeatFlowHorizontal, models.Unventilated, 0},
+		{10, models.HeatFlowUpward, models.Unventilated, 0.15},
+		{20, models.HeatFlowHorizontal, models.Unventilated, 0.175},
+		{50, models.HeatFlowHorizontal, models.Unventilated, 0.18},
+		{50, models.HeatFlowDownward, models.Unventilated, 0.21},
+		{500, models.HeatFlowDownward, models.Unventilated, 0.23},
+		{50, models.HeatFlowHorizontal, models.SlightlyVentilated, 0.09},
+		{50, models.HeatFlowHorizontal, models.WellVentilated, 0},
+	}
+
+	for _, tt := range tests {
+		got := AirLayerResistance(tt.thickness, tt.direction, tt.ventilation)
+		assertClose(t, string(tt.direction)+" "+string(tt.ventilation), got, tt.resistance, 1e-9)
+	}
+}
+
+func TestCombinedResistance(t *testing.T) {
+	// 100 mm of insulation λ 0.04 with 15 % timber λ 0.13 in a wall
+	//
+	//	R'T  = 1 / (0.85/2.67 + 0.15/0.939231)  = 2.091800
+	//	R''T = 0.13 + 0.1/0.0535 + 0.04          = 2.039159
+	//	RT   = 2.065479, e = 0.012743
+	studs := layer("Mineral wool", 100, 0.04)
+	studs.Bridge = &models.LayerBridge{Material: models.Material{Name: "Timber", Lambda: 0.13}, Fraction: 0.15}
+
+	result := Evaluate(models.InsulationResult{BaseLayers: []models.InsulationLayer{studs}, HeatFlow: models.HeatFlowHorizontal})
+	if result.Bounds == nil {
+		t.Fatal("a bridged layer needs the combined method")
+	}
+	assertClose(t, "upper bound", result.Bounds.Upper, 2.091800, 1e-6)
+	assertClose(t, "lower bound", result.Bounds.Lower, 2.039159, 1e-6)
+	assertClose(t, "relative error", result.Bounds.RelativeError, 0.012743, 1e-6)
+	assertClose(t, "RT", result.TotalResistance, 2.065479, 1e-6)
+}
+
+func TestTemperatureProfile(t *testing.T) {
+	// 0.13 + 0.2/1.0 + 0.1/0.04 + 0.04 = 2.87, so θsi = 20 - 40 · 0.13/2.87
+	result := Evaluate(models.InsulationResult{
+		BaseLayers: []models.InsulationLayer{layer("Concrete", 200, 1.0), layer("EPS", 100, 0.04)},
+		HeatFlow:   models.HeatFlowHorizontal,
+	})
+	conditions := models.DesignConditions{IndoorTemperature: 20, IndoorHumidity: 0.5, OutdoorTemperature: -20}
+	profile := TemperatureProfile(result, conditions)
+
+	if len(profile.Boundaries) != 3 {
+		t.Fatalf("got %d boundaries, want 3", len(profile.Boundaries))
+	}
+	assertClose(t, "θsi", profile.Boundaries[0].Temperature, 20-40*0.13/2.87, 1e-9)
+	assertClose(t, "θ between the layers", profile.Boundaries[1].Temperature, 20-40*0.33/2.87, 1e-9)
+	assertClose(t, "θse", profile.Boundaries[2].Temperature, -20+40*0.04/2.87, 1e-9)
+	// ISO 13788 uses Rsi = 0.25 for the surface temperature factor
+	assertClose(t, "fRsi", profile.FRsi, 1-0.25/(2.87-0.13+0.25), 1e-9)
+	if profile.MouldRisk {
+		t.Error("an insulated wall has no mould risk")
+	}
+}
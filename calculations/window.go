@@ -0,0 +1,34 @@
+package calculations
+
+import (
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// WindowU computes the thermal transmittance of a window or door per
+// EN ISO 10077-1
+//
+//	Uw = (Ag·Ug + Af·Uf + lg·Ψg) / (Ag + Af)
+//
+// with the frame equally wide on all sides, so the glazing is the overall
+// size less twice the frame width and lg is its perimeter.
+func WindowU(product models.WindowProduct) models.WindowUValue {
+	width, height := product.Width/1000, product.Height/1000
+	frame := product.FrameWidth / 1000
+
+	result := models.WindowUValue{Area: width * height}
+	if result.Area <= 0 {
+		return result
+	}
+
+	glazingWidth, glazingHeight := width-2*frame, height-2*frame
+	if glazingWidth > 0 && glazingHeight > 0 {
+		result.GlazingArea = glazingWidth * glazingHeight
+		result.GlazingEdge = 2 * (glazingWidth + glazingHeight)
+	}
+	result.FrameArea = result.Area - result.GlazingArea
+
+	result.UValue = (result.GlazingArea*product.Ug + result.FrameArea*product.Uf + result.GlazingEdge*product.PsiG) / result.Area
+	result.GlazingFactor = result.GlazingArea / result.Area
+
+	return result
+}
@@ -0,0 +1,44 @@
+package calculations
+
+import (
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+func TestWindowU(t *testing.T) {
+	tests := []struct {
+		name    string
+		product models.WindowProduct
+		want    models.WindowUValue
+	}{
+		{
+			// Ag = 1.03 · 1.28, Af = 1.8204 - 1.3184, lg = 2 (1.03 + 1.28)
+			// Uw = (1.3184 · 0.6 + 0.502 · 1.0 + 4.62 · 0.04) / 1.8204
+			name:    "triple glazed window, EN ISO 10077-1 reference size",
+			product: models.WindowProduct{Width: 1230, Height: 1480, FrameWidth: 100, Ug: 0.6, Uf: 1.0, PsiG: 0.04},
+			want:    models.WindowUValue{Area: 1.8204, GlazingArea: 1.3184, FrameArea: 0.502, GlazingEdge: 4.62, UValue: 0.811822, GlazingFactor: 0.724236},
+		},
+		{
+			name:    "frame wider than the window is all frame",
+			product: models.WindowProduct{Width: 150, Height: 1000, FrameWidth: 100, Ug: 0.6, Uf: 1.2, PsiG: 0.04},
+			want:    models.WindowUValue{Area: 0.15, FrameArea: 0.15, UValue: 1.2},
+		},
+		{
+			name:    "window without a size",
+			product: models.WindowProduct{FrameWidth: 100, Ug: 0.6, Uf: 1.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WindowU(tt.product)
+			assertClose(t, "Aw", got.Area, tt.want.Area, 1e-9)
+			assertClose(t, "Ag", got.GlazingArea, tt.want.GlazingArea, 1e-9)
+			assertClose(t, "Af", got.FrameArea, tt.want.FrameArea, 1e-9)
+			assertClose(t, "lg", got.GlazingEdge, tt.want.GlazingEdge, 1e-9)
+			assertClose(t, "Uw", got.UValue, tt.want.UValue, 1e-6)
+			assertClose(t, "Ag/Aw", got.GlazingFactor, tt.want.GlazingFactor, 1e-6)
+		})
+	}
+}
@@ -170,8 +170,16 @@ func AuthMiddleware(c *fiber.Ctx) error {
 		return flash.WithError(c, fm).Redirect("/login")
 	}
 
+	system, err := models.GetUnitSystem(user.ID)
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/login")
+	}
+
 	c.Locals("userId", userId)
 	c.Locals("username", user.Username)
+	c.Locals("units", system)
 	fromProtected = true
 
 	return c.Next()
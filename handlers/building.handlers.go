@@ -0,0 +1,628 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/kaloszer/insulationCalcHtmx/calculations"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/units"
+	"github.com/kaloszer/insulationCalcHtmx/views/building_views"
+	"github.com/sujit-baniya/flash"
+)
+
+/********** Handlers for Building Views **********/
+
+// Render the list of buildings with their heat transfer coefficients
+func HandleBuildingViewList(c *fiber.Ctx) error {
+	building := new(models.Building)
+	building.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	buildings, err := building.GetAllBuildings()
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/building/create")
+	}
+
+	losses := make([]models.HeatLoss, len(buildings))
+	for i, building := range buildings {
+		losses[i] = calculations.BuildingHeatLoss(building)
+	}
+
+	bindex := building_views.BuildingIndex(buildings, losses, unitSystem(c))
+	blist := building_views.BuildingList(
+		" | Buildings",
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		bindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(blist))
+
+	return handler(c)
+}
+
+// Render Create Building Page with success/error messages
+func HandleViewBuildingCreatePage(c *fiber.Ctx) error {
+	building := models.Building{
+		CreatedBy:              c.Locals("userId").(uint64),
+		IndoorTemperature:      20,
+		OutdoorTemperature:     -20,
+		ThermalBridgeAllowance: 0.05,
+	}
+
+	if c.Method() == "POST" {
+		fm := fiber.Map{
+			"type": "error",
+		}
+
+		if err := parseBuildingForm(c, &building); err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect("/building/create")
+		}
+
+		created, err := building.CreateBuilding()
+		if err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect("/building/list")
+		}
+
+		return c.Redirect(fmt.Sprintf("/building/%d", created.ID))
+	}
+
+	locations, err := models.LoadClimateFromTOML(climateFile)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading climate data: " + err.Error())
+	}
+
+	cindex := building_views.BuildingFormIndex("New building", building, locations, unitSystem(c))
+	create := building_views.BuildingForm(
+		" | Create Building",
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		cindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(create))
+
+	return handler(c)
+}
+
+// Render Edit Building Page with success/error messages
+func HandleViewBuildingEditPage(c *fiber.Ctx) error {
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	building, err := ownBuilding(c, "id")
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/building/list")
+	}
+
+	if c.Method() == "POST" {
+		if err := parseBuildingForm(c, &building); err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect("/building/list")
+		}
+
+		if err := building.UpdateBuilding(); err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect("/building/list")
+		}
+
+		fm = fiber.Map{
+			"type":    "success",
+			"message": "Building successfully updated!!",
+		}
+
+		return flash.WithSuccess(c, fm).Redirect("/building/list")
+	}
+
+	locations, err := models.LoadClimateFromTOML(climateFile)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading climate data: " + err.Error())
+	}
+
+	uindex := building_views.BuildingFormIndex(fmt.Sprintf("Edit Building #%d", building.ID), building, locations, unitSystem(c))
+	update := building_views.BuildingForm(
+		fmt.Sprintf(" | Edit Building #%d", building.ID),
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		uindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(update))
+
+	return handler(c)
+}
+
+// Handler Remove Building
+func HandleDeleteBuilding(c *fiber.Ctx) error {
+	idParams, _ := strconv.Atoi(c.Params("id"))
+
+	building := new(models.Building)
+	building.ID = uint64(idParams)
+	building.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	if err := building.DeleteBuilding(); err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/building/list", fiber.StatusSeeOther)
+	}
+
+	fm = fiber.Map{
+		"type":    "success",
+		"message": "Building successfully deleted!!",
+	}
+
+	return flash.WithSuccess(c, fm).Redirect("/building/list", fiber.StatusSeeOther)
+}
+
+// Render the heat loss summary of a building
+func HandleViewBuildingSummary(c *fiber.Ctx) error {
+	building, err := ownBuilding(c, "id")
+	if err != nil {
+		return flash.WithError(c, fiber.Map{
+			"type":    "error",
+			"message": fmt.Sprintf("something went wrong: %s", err),
+		}).Redirect("/building/list")
+	}
+
+	demandError := ""
+	demand, err := heatingDemand(building)
+	if err != nil {
+		demandError = err.Error()
+	}
+
+	sindex := building_views.SummaryIndex(building, calculations.BuildingHeatLoss(building), demand, demandError, unitSystem(c))
+	summary := building_views.Summary(
+		fmt.Sprintf(" | %s", building.Name),
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		sindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(summary))
+
+	return handler(c)
+}
+
+// heatingDemand computes the monthly heating demand of a building with
+// energy inputs at its climate location, nil for one without
+func heatingDemand(building models.Building) (*models.EnergyDemand, error) {
+	if building.Energy == nil {
+		return nil, nil
+	}
+
+	location, err := findClimateLocation(building.Energy.ClimateLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	demand, err := calculations.HeatingDemand(building, location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &demand, nil
+}
+
+// Render the element form, creating the element on POST
+func HandleViewElementCreatePage(c *fiber.Ctx) error {
+	building, err := ownBuilding(c, "id")
+	if err != nil {
+		return flash.WithError(c, fiber.Map{
+			"type":    "error",
+			"message": fmt.Sprintf("something went wrong: %s", err),
+		}).Redirect("/building/list")
+	}
+
+	element := models.BuildingElement{BuildingID: building.ID, Type: models.ElementWall}
+
+	return handleElementForm(c, building, element)
+}
+
+// Render the element form filled in, updating the element on POST
+func HandleViewElementEditPage(c *fiber.Ctx) error {
+	building, err := ownBuilding(c, "id")
+	if err != nil {
+		return flash.WithError(c, fiber.Map{
+			"type":    "error",
+			"message": fmt.Sprintf("something went wrong: %s", err),
+		}).Redirect("/building/list")
+	}
+
+	elementID, _ := strconv.Atoi(c.Params("elementId"))
+	element := &models.BuildingElement{ID: uint64(elementID), BuildingID: building.ID}
+	recovered, err := element.GetElementById()
+	if err != nil {
+		return flash.WithError(c, fiber.Map{
+			"type":    "error",
+			"message": fmt.Sprintf("something went wrong: %s", err),
+		}).Redirect(fmt.Sprintf("/building/%d", building.ID))
+	}
+
+	return handleElementForm(c, building, recovered)
+}
+
+func handleElementForm(c *fiber.Ctx, building models.Building, element models.BuildingElement) error {
+	summaryURL := fmt.Sprintf("/building/%d", building.ID)
+
+	if c.Method() == "POST" {
+		fm := fiber.Map{
+			"type": "error",
+		}
+
+		if err := parseElementForm(c, &element); err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect(summaryURL)
+		}
+
+		var err error
+		if element.ID == 0 {
+			err = element.CreateElement()
+		} else {
+			err = element.UpdateElement()
+		}
+		if err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect(summaryURL)
+		}
+
+		return flash.WithSuccess(c, fiber.Map{
+			"type":    "success",
+			"message": fmt.Sprintf("%s saved", element.Name),
+		}).Redirect(summaryURL)
+	}
+
+	materials, err := models.GetAllMaterials()
+	if err != nil {
+		return flash.WithError(c, fiber.Map{
+			"type":    "error",
+			"message": fmt.Sprintf("something went wrong: %s", err),
+		}).Redirect(summaryURL)
+	}
+
+	window := &models.WindowProduct{CreatedBy: building.CreatedBy}
+	windows, err := window.GetAllWindows()
+	if err != nil {
+		return flash.WithError(c, fiber.Map{
+			"type":    "error",
+			"message": fmt.Sprintf("something went wrong: %s", err),
+		}).Redirect(summaryURL)
+	}
+
+	junction := &models.Junction{CreatedBy: building.CreatedBy}
+	junctions, err := junction.GetAllJunctions()
+	if err != nil {
+		return flash.WithError(c, fiber.Map{
+			"type":    "error",
+			"message": fmt.Sprintf("something went wrong: %s", err),
+		}).Redirect(summaryURL)
+	}
+
+	eindex := building_views.ElementIndex(building, element, materials, windows, junctions, unitSystem(c))
+	page := building_views.Element(
+		fmt.Sprintf(" | %s", building.Name),
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		eindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(page))
+
+	return handler(c)
+}
+
+// Handler Remove Element
+func HandleDeleteElement(c *fiber.Ctx) error {
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	building, err := ownBuilding(c, "id")
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/building/list", fiber.StatusSeeOther)
+	}
+	summaryURL := fmt.Sprintf("/building/%d", building.ID)
+
+	elementID, _ := strconv.Atoi(c.Params("elementId"))
+	element := &models.BuildingElement{ID: uint64(elementID), BuildingID: building.ID}
+	if err := element.DeleteElement(); err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect(summaryURL, fiber.StatusSeeOther)
+	}
+
+	fm = fiber.Map{
+		"type":    "success",
+		"message": "Element successfully deleted!!",
+	}
+
+	return flash.WithSuccess(c, fm).Redirect(summaryURL, fiber.StatusSeeOther)
+}
+
+// ownBuilding loads the building named by the route parameter, if it belongs
+// to the logged in user
+func ownBuilding(c *fiber.Ctx, param string) (models.Building, error) {
+	idParams, _ := strconv.Atoi(c.Params(param))
+
+	building := new(models.Building)
+	building.ID = uint64(idParams)
+	building.CreatedBy = c.Locals("userId").(uint64)
+
+	return building.GetBuildingById()
+}
+
+// parseBuildingForm reads the building form into building, temperatures and
+// the allowance in the unit system of the user
+func parseBuildingForm(c *fiber.Ctx, building *models.Building) error {
+	building.Name = strings.Trim(c.FormValue("name"), " ")
+	building.Description = strings.Trim(c.FormValue("description"), " ")
+	if len(building.Name) < 3 {
+		return errors.New("the building name needs at least 3 characters")
+	}
+
+	system := unitSystem(c)
+	fields := []string{"indoor-temperature", "outdoor-temperature", "thermal-bridge-allowance"}
+	quantities := []units.Quantity{units.Temperature, units.Temperature, units.UValue}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := system.Parse(quantities[i], c.FormValue(field))
+		if err != nil {
+			return fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+		}
+		values[i] = value
+	}
+	if values[2] < 0 {
+		return errors.New("the thermal bridge allowance cannot be negative")
+	}
+
+	building.IndoorTemperature = values[0]
+	building.OutdoorTemperature = values[1]
+	building.ThermalBridgeAllowance = values[2]
+
+	energy, err := parseEnergyInputs(c)
+	if err != nil {
+		return err
+	}
+	building.Energy = energy
+
+	return nil
+}
+
+// parseEnergyInputs reads the energy demand section of the building form in
+// the unit system of the user, nil when no climate location is chosen. The
+// air change rate and the heat recovery read the same in every system.
+func parseEnergyInputs(c *fiber.Ctx) (*models.EnergyInputs, error) {
+	location := c.FormValue("climate-location")
+	if location == "" {
+		return nil, nil
+	}
+
+	system := unitSystem(c)
+	fields := []string{"floor-area", "volume", "internal-gains", "heat-capacity"}
+	quantities := []units.Quantity{units.Area, units.Volume, units.HeatFlux, units.HeatCapacity}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := system.Parse(quantities[i], c.FormValue(field))
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+		}
+		values[i] = value
+	}
+	if values[0] <= 0 {
+		return nil, errors.New("the heated floor area must be positive")
+	}
+
+	airChangeRate, err := strconv.ParseFloat(c.FormValue("air-change-rate"), 64)
+	if err != nil || airChangeRate < 0 {
+		return nil, errors.New("invalid air change rate")
+	}
+	heatRecovery, err := strconv.ParseFloat(c.FormValue("heat-recovery"), 64)
+	if err != nil || heatRecovery < 0 {
+		return nil, errors.New("invalid heat recovery")
+	}
+	if heatRecovery > 100 {
+		return nil, errors.New("the heat recovery cannot exceed 100 %")
+	}
+
+	return &models.EnergyInputs{
+		ClimateLocation: location,
+		FloorArea:       values[0],
+		Volume:          values[1],
+		AirChangeRate:   airChangeRate,
+		HeatRecovery:    heatRecovery / 100,
+		InternalGains:   values[2],
+		HeatCapacity:    values[3],
+	}, nil
+}
+
+// parseElementForm reads the element form into element. Elements need either
+// layers, a U-value or, for windows and doors, a product from the library.
+func parseElementForm(c *fiber.Ctx, element *models.BuildingElement) error {
+	element.Name = strings.Trim(c.FormValue("name"), " ")
+	if element.Name == "" {
+		return errors.New("the element needs a name")
+	}
+
+	element.Type = models.ElementType(c.FormValue("type"))
+	known := false
+	for _, elementType := range models.ElementTypes {
+		known = known || element.Type == elementType
+	}
+	if !known {
+		return errors.New("unknown element type")
+	}
+
+	element.Orientation = c.FormValue("orientation")
+	if element.Orientation != "" {
+		known = false
+		for _, orientation := range models.Orientations {
+			known = known || element.Orientation == orientation
+		}
+		if !known {
+			return errors.New("unknown orientation")
+		}
+	}
+
+	junctions, err := parseElementJunctions(c)
+	if err != nil {
+		return err
+	}
+	element.Junctions = junctions
+
+	element.Window = nil
+	if element.Type == models.ElementWindow || element.Type == models.ElementDoor {
+		placed, err := parseWindowProduct(c)
+		if err != nil {
+			return err
+		}
+		if placed != nil {
+			element.Window = placed
+			element.Area = float64(placed.Count) * placed.Product.Width * placed.Product.Height / 1e6
+			element.UValue = 0
+			element.Layers = nil
+			return nil
+		}
+	}
+
+	system := unitSystem(c)
+
+	area, err := system.Parse(units.Area, c.FormValue("area"))
+	if err != nil || area <= 0 {
+		return errors.New("the area must be above 0")
+	}
+	element.Area = area
+
+	element.UValue, err = system.Parse(units.UValue, c.FormValue("u-value", "0"))
+	if err != nil || element.UValue < 0 {
+		return errors.New("invalid U-value")
+	}
+
+	element.Layers, err = parseBaseLayers(c)
+	if err != nil {
+		return err
+	}
+
+	element.Ground = nil
+	element.Fixings, element.InvertedRoof = nil, nil
+	if element.Type == models.ElementGroundFloor {
+		ground, err := parseGroundFloor(c, element.Area)
+		if err != nil {
+			return err
+		}
+		element.Ground = &ground
+		return nil
+	}
+
+	if len(element.Layers) == 0 && element.UValue == 0 {
+		return errors.New("enter the layers of the element or its U-value")
+	}
+
+	if len(element.Layers) > 0 {
+		element.Fixings, element.InvertedRoof, err = parseCorrections(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseWindowProduct reads the window product placed in an element, nil when
+// none is picked. The product must belong to the user.
+func parseWindowProduct(c *fiber.Ctx) (*models.PlacedWindow, error) {
+	if c.FormValue("window-product") == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(c.FormValue("window-product"), 10, 64)
+	if err != nil {
+		return nil, errors.New("unknown window product")
+	}
+	count, err := strconv.Atoi(c.FormValue("window-count", "1"))
+	if err != nil || count < 1 {
+		return nil, errors.New("place at least one window")
+	}
+
+	window := &models.WindowProduct{ID: id, CreatedBy: c.Locals("userId").(uint64)}
+	product, err := window.GetWindowById()
+	if err != nil {
+		return nil, errors.New("unknown window product")
+	}
+
+	return &models.PlacedWindow{Product: product, Count: count}, nil
+}
+
+// parseElementJunctions reads the repeated junction rows of the element
+// form, lengths in the unit system of the user. The junctions must be in the
+// catalogue or belong to the user.
+func parseElementJunctions(c *fiber.Ctx) ([]models.PlacedJunction, error) {
+	ids := formValues(c, "junction-id")
+	lengths := formValues(c, "junction-length")
+	if len(ids) != len(lengths) {
+		return nil, errors.New("every junction needs a length")
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	junction := &models.Junction{CreatedBy: c.Locals("userId").(uint64)}
+	available, err := junction.GetAllJunctions()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Junction, len(available))
+	for _, junction := range available {
+		byID[strconv.FormatUint(junction.ID, 10)] = junction
+	}
+
+	system := unitSystem(c)
+	placed := make([]models.PlacedJunction, 0, len(ids))
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		junction, ok := byID[id]
+		if !ok {
+			return nil, errors.New("unknown junction")
+		}
+		length, err := system.Parse(units.Length, lengths[i])
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("the length of %s must be above 0", junction.Name)
+		}
+		placed = append(placed, models.PlacedJunction{Junction: junction, Length: length})
+	}
+
+	return placed, nil
+}
@@ -0,0 +1,851 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/kaloszer/insulationCalcHtmx/analysis"
+	"github.com/kaloszer/insulationCalcHtmx/calculations"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/simulation"
+	"github.com/kaloszer/insulationCalcHtmx/units"
+	"github.com/kaloszer/insulationCalcHtmx/views/material_views"
+)
+
+const (
+	climateFile     = "./assets/data/climate.toml"
+	regulationsFile = "./assets/data/regulations.toml"
+)
+
+/********** Handlers for the Insulation Calculator **********/
+
+// calculatorInput is everything the calculator form submits
+type calculatorInput struct {
+	construction  models.Construction
+	desiredUValue float64
+	maxLayers     int
+	maxThickness  float64
+	objective     models.Objective
+	carbonPrice   float64
+	minFireClass  models.FireClass
+	minTimeLag    float64   // h, 0 for none
+	outdoorSeries []float64 // hourly °C for the transient simulation, empty for none
+	materials     []models.Material
+	conditions    models.DesignConditions
+	location      models.ClimateLocation
+	economics     models.EconomicInputs
+	regulation    models.Regulation
+	elementType   models.ElementType // the limit of the regulation it is checked against
+	junctions     []models.PlacedJunction
+	elementArea   float64 // m², spreads Σ ψ·l of the junctions over the element
+}
+
+// HandleInsulationCalculatorPage renders the insulation calculator page
+func HandleInsulationCalculatorPage(c *fiber.Ctx) error {
+	materials, err := models.GetAllMaterials()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading materials: " + err.Error())
+	}
+
+	locations, err := models.LoadClimateFromTOML(climateFile)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading climate data: " + err.Error())
+	}
+
+	regulations, err := models.LoadRegulationsFromTOML(regulationsFile)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading regulations: " + err.Error())
+	}
+
+	junction := &models.Junction{CreatedBy: c.Locals("userId").(uint64)}
+	junctions, err := junction.GetAllJunctions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading junctions: " + err.Error())
+	}
+
+	baseLayers := []models.InsulationLayer{defaultBaseLayer(materials)}
+
+	handler := adaptor.HTTPHandler(templ.Handler(material_views.InsulationCalculatorPage(materials, baseLayers, locations, regulations, junctions, unitSystem(c))))
+
+	return handler(c)
+}
+
+// HandleBaseLayers adds, removes or reorders the existing layers of the
+// calculator form and re-renders the layer editor
+func HandleBaseLayers(c *fiber.Ctx) error {
+	materials, err := models.GetAllMaterials()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading materials: " + err.Error())
+	}
+
+	layers, err := parseBaseLayers(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	index, err := strconv.Atoi(c.Params("index", "0"))
+	if err != nil || index < 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid layer index")
+	}
+
+	switch c.Params("action") {
+	case "add":
+		layers = append(layers, defaultBaseLayer(materials))
+	case "remove":
+		if index < len(layers) {
+			layers = append(layers[:index], layers[index+1:]...)
+		}
+	case "up":
+		if index > 0 && index < len(layers) {
+			layers[index-1], layers[index] = layers[index], layers[index-1]
+		}
+	case "down":
+		if index < len(layers)-1 {
+			layers[index], layers[index+1] = layers[index+1], layers[index]
+		}
+	case "refresh":
+		// A material changed, e.g. to an air layer, which has other fields
+	default:
+		return c.Status(fiber.StatusBadRequest).SendString("Unknown layer action")
+	}
+
+	return material_views.BaseLayers(layers, materials, unitSystem(c)).Render(c.Context(), c.Response().BodyWriter())
+}
+
+// HandleCalculateInsulation searches the Pareto front of insulation options
+// and shows it together with the recommended solution
+func HandleCalculateInsulation(c *fiber.Ctx) error {
+	input, err := parseCalculatorForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if len(input.materials) == 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Select at least one insulation material")
+	}
+
+	// Perform optimization
+	optimization := calculations.Optimize(input.construction, input.materials, calculations.OptimizerOptions{
+		TargetUValue: input.desiredUValue,
+		MaxLayers:    input.maxLayers,
+		MaxThickness: input.maxThickness,
+		Objective:    input.objective,
+		CarbonPrice:  input.carbonPrice,
+		MinFireClass: input.minFireClass,
+		MinTimeLag:   input.minTimeLag,
+	})
+	if len(optimization.Excluded) == len(input.materials) {
+		return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("None of the selected materials is fire class %s or better", input.minFireClass))
+	}
+	recommended := analyseResult(optimization.Recommended(), input)
+
+	// Render the result using the templ component
+	return material_views.OptimizationResult(optimization, recommended, unitSystem(c)).Render(c.Context(), c.Response().BodyWriter())
+}
+
+// HandleCalculateSolution shows the details of one point of the Pareto
+// front, sent as "material:thickness" pairs in the solution field
+func HandleCalculateSolution(c *fiber.Ctx) error {
+	input, err := parseCalculatorForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	layers, err := parseSolution(c.FormValue("solution"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	result := input.construction.Result()
+	result.Layers = layers
+	result = analyseResult(calculations.Evaluate(result), input)
+
+	return material_views.InsulationResult(result, unitSystem(c)).Render(c.Context(), c.Response().BodyWriter())
+}
+
+// HandleSimulationCSV downloads the transient simulation of the build-up in
+// the simulation-solution field as CSV
+func HandleSimulationCSV(c *fiber.Ctx) error {
+	input, err := parseCalculatorForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if len(input.outdoorSeries) == 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Enter hourly outdoor temperatures to simulate")
+	}
+	if input.construction.Ground != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("A floor on the ground cannot be simulated")
+	}
+
+	layers, err := parseSolution(c.FormValue("simulation-solution"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	result := input.construction.Result()
+	result.Layers = layers
+	transient := simulation.Run(calculations.Evaluate(result), models.SimulationInputs{
+		IndoorTemperature:   input.conditions.IndoorTemperature,
+		OutdoorTemperatures: input.outdoorSeries,
+	})
+
+	c.Attachment("simulation.csv")
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+
+	return simulation.WriteCSV(c.Response().BodyWriter(), transient, unitSystem(c))
+}
+
+// analyseResult adds the temperature profile, the condensation check and the
+// lifecycle cost to a calculated build-up
+func analyseResult(result models.InsulationResult, input calculatorInput) models.InsulationResult {
+	temperature := calculations.TemperatureProfile(result, input.conditions)
+	result.Temperature = &temperature
+	// ISO 13788 and ISO 13786 do not cover constructions in contact with the ground
+	if result.Ground == nil {
+		condensation := calculations.AnalyseCondensation(result, input.conditions, input.location)
+		result.Condensation = &condensation
+		dynamic := calculations.DynamicProperties(result)
+		result.Dynamic = &dynamic
+		if len(input.outdoorSeries) > 0 {
+			transient := simulation.Run(result, models.SimulationInputs{
+				IndoorTemperature:   input.conditions.IndoorTemperature,
+				OutdoorTemperatures: input.outdoorSeries,
+			})
+			result.Simulation = &transient
+		}
+	}
+	lifecycle := analysis.Lifecycle(result, input.economics)
+	result.Lifecycle = &lifecycle
+	if compliance, ok := input.regulation.Check(input.elementType, result.TotalUValue); ok {
+		result.Compliance = &compliance
+	}
+	if len(input.junctions) > 0 {
+		junctions := calculations.ElementJunctionLoss(result.TotalUValue, input.elementArea, input.junctions)
+		result.Junctions = &junctions
+	}
+
+	return result
+}
+
+// parseCalculatorForm reads and validates the calculator form
+func parseCalculatorForm(c *fiber.Ctx) (calculatorInput, error) {
+	input := calculatorInput{}
+	system := unitSystem(c)
+
+	desiredUValue, err := system.Parse(units.UValue, c.FormValue("desired-u-value"))
+	if err != nil || desiredUValue <= 0 {
+		return input, errors.New("invalid desired U-value")
+	}
+	input.desiredUValue = desiredUValue
+
+	maxLayers, err := strconv.Atoi(c.FormValue("max-layers", "2"))
+	if err != nil || maxLayers < 1 || maxLayers > 3 {
+		return input, errors.New("the number of insulation layers must be between 1 and 3")
+	}
+	input.maxLayers = maxLayers
+
+	// The thickness limit is optional, empty means none
+	if value := strings.TrimSpace(c.FormValue("max-thickness")); value != "" {
+		maxThickness, err := system.Parse(units.Thickness, value)
+		if err != nil || maxThickness < 0 {
+			return input, errors.New("invalid maximum added thickness")
+		}
+		input.maxThickness = maxThickness
+	}
+
+	input.objective = models.Objective(c.FormValue("objective", string(models.ObjectiveCost)))
+	switch input.objective {
+	case models.ObjectiveCost, models.ObjectiveCarbon:
+	case models.ObjectiveWeighted:
+		carbonPrice, err := strconv.ParseFloat(c.FormValue("carbon-price"), 64)
+		if err != nil || carbonPrice < 0 {
+			return input, errors.New("invalid carbon price")
+		}
+		input.carbonPrice = carbonPrice
+	default:
+		return input, errors.New("unknown optimization objective")
+	}
+
+	// An empty minimum fire class allows any material
+	input.minFireClass = models.FireClass(c.FormValue("min-fire-class"))
+	if input.minFireClass != "" && !input.minFireClass.Valid() {
+		return input, errors.New("unknown fire class")
+	}
+
+	// The time lag is optional too, empty means none
+	if value := strings.TrimSpace(c.FormValue("min-time-lag")); value != "" {
+		minTimeLag, err := strconv.ParseFloat(value, 64)
+		if err != nil || minTimeLag < 0 || minTimeLag >= models.DynamicPeriod {
+			return input, fmt.Errorf("the minimum time lag must be between 0 and %.0f h", models.DynamicPeriod)
+		}
+		input.minTimeLag = minTimeLag
+	}
+
+	baseLayers, err := parseBaseLayers(c)
+	if err != nil {
+		return input, err
+	}
+	input.construction = models.Construction{
+		Layers:   baseLayers,
+		HeatFlow: models.HeatFlowDirection(c.FormValue("heat-flow", string(models.HeatFlowHorizontal))),
+	}
+
+	if c.FormValue("ground-floor") == "on" {
+		area, err := system.Parse(units.Area, c.FormValue("floor-area"))
+		if err != nil || area <= 0 {
+			return input, errors.New("the floor area must be above 0")
+		}
+
+		ground, err := parseGroundFloor(c, area)
+		if err != nil {
+			return input, err
+		}
+		input.construction.Ground = &ground
+		input.construction.HeatFlow = models.HeatFlowDownward
+		// ISO 13786 covers elements between two air volumes only
+		if input.minTimeLag > 0 {
+			return input, errors.New("a minimum time lag cannot be required of a floor on the ground")
+		}
+	} else {
+		input.construction.Fixings, input.construction.InvertedRoof, err = parseCorrections(c)
+		if err != nil {
+			return input, err
+		}
+	}
+
+	input.construction.Lambda, err = parseLambdaConditions(c)
+	if err != nil {
+		return input, err
+	}
+
+	input.elementType = models.ElementType(c.FormValue("element-type", string(models.ElementWall)))
+	if input.construction.Ground != nil {
+		input.elementType = models.ElementGroundFloor
+	}
+	known := false
+	for _, elementType := range models.LayeredElementTypes {
+		known = known || input.elementType == elementType
+	}
+	if !known {
+		return input, errors.New("unknown element type")
+	}
+
+	input.regulation, err = findRegulation(c.FormValue("regulation"))
+	if err != nil {
+		return input, err
+	}
+
+	// Get selected materials
+	input.materials, err = models.GetMaterialsByIDs(formValues(c, "insulation-materials"))
+	if err != nil {
+		return input, fmt.Errorf("error fetching materials: %w", err)
+	}
+	// Costs of different currencies cannot be added up
+	if _, err := models.CommonCurrency(input.materials); err != nil {
+		return input, err
+	}
+
+	input.conditions, err = parseDesignConditions(c)
+	if err != nil {
+		return input, err
+	}
+
+	input.location, err = findClimateLocation(c.FormValue("climate-location"))
+	if err != nil {
+		return input, err
+	}
+
+	input.economics, err = parseEconomicInputs(c)
+	if err != nil {
+		return input, err
+	}
+
+	input.outdoorSeries, err = parseOutdoorSeries(c.FormValue("outdoor-series"), system)
+	if err != nil {
+		return input, err
+	}
+
+	// Junctions are optional, they need the area of the element to be
+	// spread over, a floor on the ground uses its floor area
+	input.junctions, err = parseElementJunctions(c)
+	if err != nil {
+		return input, err
+	}
+	if len(input.junctions) > 0 {
+		if input.construction.Ground != nil {
+			input.elementArea = input.construction.Ground.Area
+		} else {
+			input.elementArea, err = system.Parse(units.Area, c.FormValue("element-area"))
+			if err != nil || input.elementArea <= 0 {
+				return input, errors.New("the element area must be above 0 to spread the junctions over it")
+			}
+		}
+	}
+
+	return input, nil
+}
+
+// formValues returns every value submitted for a repeated form field, in the
+// order they were sent
+func formValues(c *fiber.Ctx, key string) []string {
+	values := []string{}
+	for _, value := range c.Request().PostArgs().PeekMulti(key) {
+		values = append(values, string(value))
+	}
+
+	return values
+}
+
+// parseBaseLayers reads the repeated base-layer fields of the calculator form,
+// thicknesses in the unit system of the user
+func parseBaseLayers(c *fiber.Ctx) ([]models.InsulationLayer, error) {
+	ids := formValues(c, "base-layer-material")
+	thicknesses := formValues(c, "base-layer-thickness")
+	if len(ids) != len(thicknesses) {
+		return nil, errors.New("every layer needs a material and a thickness")
+	}
+
+	layers, err := buildLayers(ids, thicknesses, unitSystem(c))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addAirLayers(c, layers); err != nil {
+		return nil, err
+	}
+
+	bridges := formValues(c, "base-layer-bridge")
+	fractions := formValues(c, "base-layer-bridge-fraction")
+	if len(bridges) == 0 {
+		return layers, nil
+	}
+	if len(bridges) != len(layers) || len(fractions) != len(layers) {
+		return nil, errors.New("every layer needs a bridging material, or none, and a fraction")
+	}
+
+	return addBridges(layers, bridges, fractions)
+}
+
+// addAirLayers reads the heat flow and the ventilation class of the air
+// layers among layers
+func addAirLayers(c *fiber.Ctx, layers []models.InsulationLayer) error {
+	ventilations := formValues(c, "base-layer-air-ventilation")
+	directions := formValues(c, "base-layer-air-heat-flow")
+	if len(ventilations) == 0 {
+		return nil
+	}
+	if len(ventilations) != len(layers) || len(directions) != len(layers) {
+		return errors.New("every layer needs a ventilation class and a heat flow direction")
+	}
+
+	for i := range layers {
+		if layers[i].Air == nil {
+			continue
+		}
+
+		ventilation := models.VentilationClass(ventilations[i])
+		known := false
+		for _, class := range models.VentilationClasses {
+			known = known || ventilation == class
+		}
+		if !known {
+			return fmt.Errorf("unknown ventilation class for layer %d", i+1)
+		}
+
+		direction := models.HeatFlowDirection(directions[i])
+		switch direction {
+		case "", models.HeatFlowHorizontal, models.HeatFlowUpward, models.HeatFlowDownward:
+		default:
+			return fmt.Errorf("unknown heat flow direction for layer %d", i+1)
+		}
+
+		layers[i].Air = &models.AirLayer{HeatFlow: direction, Ventilation: ventilation}
+	}
+
+	return nil
+}
+
+// addBridges sets the bridging material of the layers that have one, the
+// area fractions are entered in %
+func addBridges(layers []models.InsulationLayer, ids, fractions []string) ([]models.InsulationLayer, error) {
+	used := []string{}
+	for _, id := range ids {
+		if id != "" {
+			used = append(used, id)
+		}
+	}
+	materials, err := models.GetMaterialsByIDs(used)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Material, len(materials))
+	for _, material := range materials {
+		byID[fmt.Sprint(material.ID)] = material
+	}
+
+	for i, id := range ids {
+		if id == "" || layers[i].Air != nil {
+			continue
+		}
+
+		material, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown bridging material #%s", id)
+		}
+
+		fraction, err := strconv.ParseFloat(fractions[i], 64)
+		if err != nil || fraction <= 0 || fraction >= 100 {
+			return nil, fmt.Errorf("the bridged area of layer %d must be between 0 and 100 %%", i+1)
+		}
+
+		layers[i].Bridge = &models.LayerBridge{Material: material, Fraction: fraction / 100}
+	}
+
+	return layers, nil
+}
+
+// parseSolution reads a build-up encoded as "material:thickness" pairs
+// separated by commas, e.g. "7:100,2:60". A thickness stacked from several
+// boards is written as their sum, e.g. "7:100+50".
+func parseSolution(solution string) ([]models.InsulationLayer, error) {
+	ids := []string{}
+	thicknesses := []string{}
+	stacks := [][]float64{}
+	for _, pair := range strings.Split(solution, ",") {
+		if pair == "" {
+			continue
+		}
+
+		id, thickness, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid solution layer %q", pair)
+		}
+
+		boards := []float64{}
+		total := 0.0
+		for _, part := range strings.Split(thickness, "+") {
+			board, err := strconv.ParseFloat(part, 64)
+			if err != nil || board <= 0 {
+				return nil, fmt.Errorf("invalid solution layer %q", pair)
+			}
+			boards = append(boards, board)
+			total += board
+		}
+		if len(boards) < 2 {
+			boards = nil
+		}
+
+		ids = append(ids, id)
+		thicknesses = append(thicknesses, strconv.FormatFloat(total, 'f', -1, 64))
+		stacks = append(stacks, boards)
+	}
+
+	layers, err := buildLayers(ids, thicknesses, units.Metric)
+	if err != nil {
+		return nil, err
+	}
+	for i := range layers {
+		layers[i].Boards = stacks[i]
+	}
+
+	return layers, nil
+}
+
+// buildLayers looks the materials up and pairs them with their thicknesses,
+// keeping the given order. The id "air" makes an unventilated air layer.
+func buildLayers(ids, thicknesses []string, system units.System) ([]models.InsulationLayer, error) {
+	materialIDs := []string{}
+	for _, id := range ids {
+		if id != models.AirMaterialID {
+			materialIDs = append(materialIDs, id)
+		}
+	}
+	materials, err := models.GetMaterialsByIDs(materialIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Material, len(materials))
+	for _, material := range materials {
+		byID[fmt.Sprint(material.ID)] = material
+	}
+
+	layers := make([]models.InsulationLayer, 0, len(ids))
+	for i, id := range ids {
+		thickness, err := system.Parse(units.Thickness, thicknesses[i])
+		if err != nil || thickness <= 0 {
+			return nil, fmt.Errorf("invalid thickness for layer %d", i+1)
+		}
+
+		if id == models.AirMaterialID {
+			layers = append(layers, models.InsulationLayer{
+				Material:  models.AirMaterial(),
+				Thickness: thickness,
+				Air:       &models.AirLayer{Ventilation: models.Unventilated},
+			})
+			continue
+		}
+
+		material, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown material #%s", id)
+		}
+
+		layers = append(layers, models.InsulationLayer{Material: material, Thickness: thickness})
+	}
+
+	return layers, nil
+}
+
+// parseDesignConditions reads the indoor and outdoor conditions of the
+// calculator form, relative humidities are entered in %
+func parseDesignConditions(c *fiber.Ctx) (models.DesignConditions, error) {
+	system := unitSystem(c)
+	temperatures := []string{"indoor-temperature", "outdoor-temperature"}
+	humidities := []string{"indoor-humidity", "outdoor-humidity"}
+	values := make([]float64, 4)
+	for i, field := range temperatures {
+		value, err := system.Parse(units.Temperature, c.FormValue(field))
+		if err != nil {
+			return models.DesignConditions{}, fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+		}
+		values[i] = value
+	}
+	for i, field := range humidities {
+		value, err := strconv.ParseFloat(c.FormValue(field), 64)
+		if err != nil {
+			return models.DesignConditions{}, fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+		}
+		values[2+i] = value
+	}
+
+	conditions := models.DesignConditions{
+		IndoorTemperature:  values[0],
+		IndoorHumidity:     values[2] / 100,
+		OutdoorTemperature: values[1],
+		OutdoorHumidity:    values[3] / 100,
+	}
+	if conditions.IndoorTemperature <= conditions.OutdoorTemperature {
+		return conditions, errors.New("the indoor temperature must be above the outdoor temperature")
+	}
+	if conditions.IndoorHumidity <= 0 || conditions.IndoorHumidity > 1 || conditions.OutdoorHumidity <= 0 || conditions.OutdoorHumidity > 1 {
+		return conditions, errors.New("relative humidity must be between 0 and 100 %")
+	}
+
+	return conditions, nil
+}
+
+// parseGroundFloor reads the ISO 13370 fields of a slab-on-ground floor
+// with the given floor area
+func parseGroundFloor(c *fiber.Ctx, area float64) (models.GroundFloor, error) {
+	ground := models.GroundFloor{
+		Area:           area,
+		Soil:           models.SoilType(c.FormValue("soil-type", string(models.SoilClay))),
+		EdgeInsulation: models.EdgeInsulation(c.FormValue("edge-insulation")),
+	}
+
+	known := false
+	for _, soil := range models.SoilTypes {
+		known = known || ground.Soil == soil
+	}
+	if !known {
+		return ground, errors.New("unknown soil type")
+	}
+
+	system := unitSystem(c)
+
+	var err error
+	ground.Perimeter, err = system.Parse(units.Length, c.FormValue("exposed-perimeter"))
+	if err != nil || ground.Perimeter <= 0 {
+		return ground, errors.New("the exposed perimeter must be above 0")
+	}
+
+	ground.WallThickness, err = system.Parse(units.Thickness, c.FormValue("wall-thickness", "0"))
+	if err != nil || ground.WallThickness < 0 {
+		return ground, errors.New("invalid wall thickness")
+	}
+
+	switch ground.EdgeInsulation {
+	case models.EdgeInsulationNone:
+	case models.EdgeInsulationHorizontal, models.EdgeInsulationVertical:
+		fields := []string{"edge-insulation-width", "edge-insulation-thickness", "edge-insulation-lambda"}
+		quantities := []units.Quantity{units.Length, units.Thickness, units.Conductivity}
+		values := make([]float64, len(fields))
+		for i, field := range fields {
+			value, err := system.Parse(quantities[i], c.FormValue(field))
+			if err != nil || value <= 0 {
+				return ground, fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+			}
+			values[i] = value
+		}
+		ground.EdgeInsulationWidth = values[0]
+		ground.EdgeInsulationThickness = values[1]
+		ground.EdgeInsulationLambda = values[2]
+	default:
+		return ground, errors.New("unknown edge insulation")
+	}
+
+	return ground, nil
+}
+
+// parseCorrections reads the fixings and the inverted roof data, each only
+// when its checkbox is ticked
+func parseCorrections(c *fiber.Ctx) (*models.Fixings, *models.InvertedRoof, error) {
+	system := unitSystem(c)
+
+	var fixings *models.Fixings
+	if c.FormValue("fixings") == "on" {
+		fields := []string{"fixing-count", "fixing-diameter", "fixing-conductivity", "fixing-penetration"}
+		quantities := []units.Quantity{units.PerArea, units.Thickness, units.Conductivity, units.Thickness}
+		values := make([]float64, len(fields))
+		for i, field := range fields {
+			value, err := system.Parse(quantities[i], c.FormValue(field))
+			if err != nil || value <= 0 {
+				return nil, nil, fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+			}
+			values[i] = value
+		}
+		fixings = &models.Fixings{Count: values[0], Diameter: values[1], Conductivity: values[2], Penetration: values[3]}
+	}
+
+	var roof *models.InvertedRoof
+	if c.FormValue("inverted-roof") == "on" {
+		precipitation, err := system.Parse(units.Thickness, c.FormValue("precipitation"))
+		if err != nil || precipitation < 0 {
+			return nil, nil, errors.New("invalid precipitation rate")
+		}
+
+		drainage := models.RoofDrainage(c.FormValue("roof-drainage", string(models.DrainageOpen)))
+		if drainage != models.DrainageOpen && drainage != models.DrainageClosed {
+			return nil, nil, errors.New("unknown roof drainage")
+		}
+		roof = &models.InvertedRoof{Precipitation: precipitation, Drainage: drainage}
+	}
+
+	return fixings, roof, nil
+}
+
+// parseLambdaConditions reads the lambda basis, nil keeps the declared
+// lambdas
+func parseLambdaConditions(c *fiber.Ctx) (*models.LambdaConditions, error) {
+	switch c.FormValue("lambda-basis", "declared") {
+	case "declared":
+		return nil, nil
+	case "design":
+		temperature, err := unitSystem(c).Parse(units.Temperature, c.FormValue("lambda-temperature", "10"))
+		if err != nil || temperature < -50 || temperature > 100 {
+			return nil, errors.New("invalid mean insulation temperature")
+		}
+		return &models.LambdaConditions{Temperature: temperature}, nil
+	default:
+		return nil, errors.New("unknown lambda basis")
+	}
+}
+
+// parseOutdoorSeries reads the hourly outdoor temperatures of the transient
+// simulation, separated by commas, semicolons or whitespace
+func parseOutdoorSeries(value string, system units.System) ([]float64, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) < 2 || len(fields) > simulation.MaxHours {
+		return nil, fmt.Errorf("the outdoor temperature series needs between 2 and %d hourly values", simulation.MaxHours)
+	}
+
+	series := make([]float64, len(fields))
+	for i, field := range fields {
+		temperature, err := system.Parse(units.Temperature, field)
+		if err != nil || temperature < -60 || temperature > 60 {
+			return nil, fmt.Errorf("invalid outdoor temperature %q in hour %d", field, i)
+		}
+		series[i] = temperature
+	}
+
+	return series, nil
+}
+
+// parseEconomicInputs reads the lifecycle cost assumptions of the
+// calculator form, efficiency and rates are entered in %
+func parseEconomicInputs(c *fiber.Ctx) (models.EconomicInputs, error) {
+	fields := []string{"degree-days", "efficiency", "energy-price", "escalation", "discount-rate"}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(c.FormValue(field), 64)
+		if err != nil || value < 0 {
+			return models.EconomicInputs{}, fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+		}
+		values[i] = value
+	}
+	if values[1] <= 0 {
+		return models.EconomicInputs{}, errors.New("the heating system efficiency must be above 0 %")
+	}
+
+	period, err := strconv.Atoi(c.FormValue("analysis-period"))
+	if err != nil || period < 1 || period > 100 {
+		return models.EconomicInputs{}, errors.New("the analysis period must be between 1 and 100 years")
+	}
+
+	return models.EconomicInputs{
+		DegreeDays:     unitSystem(c).ToMetric(units.TemperatureDifference, values[0]),
+		Efficiency:     values[1] / 100,
+		EnergyPrice:    values[2],
+		Escalation:     values[3] / 100,
+		DiscountRate:   values[4] / 100,
+		AnalysisPeriod: period,
+	}, nil
+}
+
+// findRegulation looks a regulation up by id in the bundled rules file
+func findRegulation(id string) (models.Regulation, error) {
+	regulations, err := models.LoadRegulationsFromTOML(regulationsFile)
+	if err != nil {
+		return models.Regulation{}, err
+	}
+
+	for _, regulation := range regulations {
+		if regulation.ID == id {
+			return regulation, nil
+		}
+	}
+
+	return models.Regulation{}, fmt.Errorf("unknown regulation %q", id)
+}
+
+// findClimateLocation looks a location up by name in the bundled climate table
+func findClimateLocation(name string) (models.ClimateLocation, error) {
+	locations, err := models.LoadClimateFromTOML(climateFile)
+	if err != nil {
+		return models.ClimateLocation{}, err
+	}
+
+	for _, location := range locations {
+		if location.Name == name {
+			return location, nil
+		}
+	}
+
+	return models.ClimateLocation{}, fmt.Errorf("unknown climate location %q", name)
+}
+
+// defaultBaseLayer is the layer offered when a new row is added, the first
+// wall material at its catalogue thickness
+func defaultBaseLayer(materials []models.Material) models.InsulationLayer {
+	for _, material := range materials {
+		if material.Type == models.MaterialWall {
+			return models.InsulationLayer{Material: material, Thickness: material.Thickness * 1000} // TOML thickness is in m
+		}
+	}
+
+	if len(materials) == 0 {
+		return models.InsulationLayer{}
+	}
+
+	return models.InsulationLayer{Material: materials[0], Thickness: materials[0].Thickness * 1000}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/units"
+	"github.com/kaloszer/insulationCalcHtmx/views/junction_views"
+	"github.com/sujit-baniya/flash"
+)
+
+/********** Handlers for Junction Views **********/
+
+// Render the junction catalogue with the junctions of the user
+func HandleJunctionViewList(c *fiber.Ctx) error {
+	junction := new(models.Junction)
+	junction.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	junctions, err := junction.GetAllJunctions()
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/junction/create")
+	}
+
+	jindex := junction_views.JunctionIndex(junctions, junction.CreatedBy, unitSystem(c))
+	jlist := junction_views.JunctionList(
+		" | Junctions",
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		jindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(jlist))
+
+	return handler(c)
+}
+
+// Render Create Junction Page with success/error messages
+func HandleViewJunctionCreatePage(c *fiber.Ctx) error {
+	junction := models.Junction{
+		CreatedBy:  c.Locals("userId").(uint64),
+		Type:       models.JunctionBalcony,
+		Dimensions: models.DimensionsExternal,
+	}
+
+	if c.Method() == "POST" {
+		fm := fiber.Map{
+			"type": "error",
+		}
+
+		if err := parseJunctionForm(c, &junction); err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect("/junction/create")
+		}
+
+		if err := junction.CreateJunction(); err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect("/junction/list")
+		}
+
+		return flash.WithSuccess(c, fiber.Map{
+			"type":    "success",
+			"message": "Junction successfully created!!",
+		}).Redirect("/junction/list")
+	}
+
+	cindex := junction_views.JunctionFormIndex("New junction", junction, unitSystem(c))
+	create := junction_views.JunctionForm(
+		" | Create Junction",
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		cindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(create))
+
+	return handler(c)
+}
+
+// Render Edit Junction Page with success/error messages
+func HandleViewJunctionEditPage(c *fiber.Ctx) error {
+	idParams, _ := strconv.Atoi(c.Params("id"))
+
+	junction := new(models.Junction)
+	junction.ID = uint64(idParams)
+	junction.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	recovered, err := junction.GetJunctionById()
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/junction/list")
+	}
+
+	if c.Method() == "POST" {
+		if err := parseJunctionForm(c, &recovered); err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect("/junction/list")
+		}
+
+		if err := recovered.UpdateJunction(); err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect("/junction/list")
+		}
+
+		fm = fiber.Map{
+			"type":    "success",
+			"message": "Junction successfully updated!!",
+		}
+
+		return flash.WithSuccess(c, fm).Redirect("/junction/list")
+	}
+
+	uindex := junction_views.JunctionFormIndex(fmt.Sprintf("Edit Junction #%d", recovered.ID), recovered, unitSystem(c))
+	update := junction_views.JunctionForm(
+		fmt.Sprintf(" | Edit Junction #%d", recovered.ID),
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		uindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(update))
+
+	return handler(c)
+}
+
+// Handler Remove Junction
+func HandleDeleteJunction(c *fiber.Ctx) error {
+	idParams, _ := strconv.Atoi(c.Params("id"))
+
+	junction := new(models.Junction)
+	junction.ID = uint64(idParams)
+	junction.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	if err := junction.DeleteJunction(); err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/junction/list", fiber.StatusSeeOther)
+	}
+
+	fm = fiber.Map{
+		"type":    "success",
+		"message": "Junction successfully deleted!!",
+	}
+
+	return flash.WithSuccess(c, fm).Redirect("/junction/list", fiber.StatusSeeOther)
+}
+
+// parseJunctionForm reads the junction form into junction, ψ in the unit
+// system of the user
+func parseJunctionForm(c *fiber.Ctx, junction *models.Junction) error {
+	junction.Code = strings.Trim(c.FormValue("code"), " ")
+	junction.Name = strings.Trim(c.FormValue("name"), " ")
+	junction.Description = strings.Trim(c.FormValue("description"), " ")
+	if len(junction.Name) < 3 {
+		return errors.New("the junction name needs at least 3 characters")
+	}
+
+	junction.Type = models.JunctionType(c.FormValue("type"))
+	known := false
+	for _, junctionType := range models.JunctionTypes {
+		known = known || junction.Type == junctionType
+	}
+	if !known {
+		return errors.New("unknown junction type")
+	}
+
+	junction.Dimensions = models.DimensionSystem(c.FormValue("dimensions"))
+	known = false
+	for _, dimensions := range models.DimensionSystems {
+		known = known || junction.Dimensions == dimensions
+	}
+	if !known {
+		return errors.New("unknown dimension system")
+	}
+
+	// ψ can be negative, at external corners for one
+	psi, err := unitSystem(c).Parse(units.LinearTransmittance, c.FormValue("psi"))
+	if err != nil {
+		return errors.New("invalid psi-value")
+	}
+	junction.Psi = psi
+
+	return nil
+}
@@ -1,11 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"math"
 	"os"
-	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -14,6 +13,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/units"
 	"github.com/kaloszer/insulationCalcHtmx/views/material_views"
 	"github.com/olekukonko/tablewriter"
 	"github.com/sujit-baniya/flash"
@@ -21,19 +21,46 @@ import (
 
 // HandleViewMaterialCreatePage handler
 func HandleViewMaterialCreatePage(c *fiber.Ctx) error {
+	system := unitSystem(c)
+
 	if c.Method() == "POST" {
-		lambda, err := strconv.ParseFloat(c.FormValue("lambda"), 64)
+		lambda, err := system.Parse(units.Conductivity, c.FormValue("lambda"))
 		if err != nil {
 			return flash.WithError(c, fiber.Map{
 				"error":   true,
 				"message": "Invalid lambda value",
 			}).Redirect("/material/create")
 		}
-		price, err := strconv.ParseFloat(c.FormValue("price"), 64)
+
+		mu, err := strconv.ParseFloat(c.FormValue("mu", "1"), 64)
+		if err != nil || mu < 1 {
+			return flash.WithError(c, fiber.Map{
+				"error":   true,
+				"message": "Invalid vapour diffusion resistance factor",
+			}).Redirect("/material/create")
+		}
+
+		thicknesses, err := parseThicknesses(c.FormValue("thicknesses"), system)
+		if err != nil {
+			return flash.WithError(c, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			}).Redirect("/material/create")
+		}
+
+		density, gwp, basis, err := parseEmbodiedCarbon(c, system)
 		if err != nil {
 			return flash.WithError(c, fiber.Map{
 				"error":   true,
-				"message": "Invalid price value",
+				"message": err.Error(),
+			}).Redirect("/material/create")
+		}
+
+		conversion, err := parseConversionFactors(c)
+		if err != nil {
+			return flash.WithError(c, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
 			}).Redirect("/material/create")
 		}
 
@@ -41,8 +68,49 @@ func HandleViewMaterialCreatePage(c *fiber.Ctx) error {
 			CreatedBy:   c.Locals("userId").(uint64),
 			Name:        c.FormValue("name"),
 			Lambda:      lambda,
-			Price:       price,
+			Thicknesses: thicknesses,
+			Mu:          mu,
+			Density:     density,
+			GWP:         gwp,
+			GWPBasis:    basis,
 			Description: c.FormValue("description"),
+
+			TemperatureCoefficient: conversion[0],
+			MoistureCoefficient:    conversion[1],
+			MoistureContent:        conversion[2],
+			AgeingFactor:           conversion[3],
+		}
+
+		err = parsePrice(c, system, &material)
+		if err != nil {
+			return flash.WithError(c, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			}).Redirect("/material/create")
+		}
+
+		material.Type, err = parseMaterialType(c)
+		if err != nil {
+			return flash.WithError(c, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			}).Redirect("/material/create")
+		}
+
+		material.FireClass, err = parseFireClass(c)
+		if err != nil {
+			return flash.WithError(c, fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			}).Redirect("/material/create")
+		}
+
+		material.SpecificHeat, err = system.Parse(units.SpecificHeat, c.FormValue("specific-heat", "0"))
+		if err != nil || material.SpecificHeat < 0 {
+			return flash.WithError(c, fiber.Map{
+				"error":   true,
+				"message": "Invalid specific heat capacity",
+			}).Redirect("/material/create")
 		}
 
 		err = models.AddMaterial(material)
@@ -90,7 +158,7 @@ func HandleViewMaterialCreatePage(c *fiber.Ctx) error {
 	table.SetCaption(true, "Materials")
 	table.Render()
 
-	cindex := material_views.CreateIndex()
+	cindex := material_views.CreateIndex(system)
 	create := material_views.Create(
 		" | Create a new material",
 		fromProtected,
@@ -120,7 +188,7 @@ func HandleMaterialViewList(c *fiber.Ctx) error {
 		return flash.WithError(c, fm).Redirect("/material/create")
 	}
 
-	tindex := material_views.MaterialIndex(materialsSlice)
+	tindex := material_views.MaterialIndex(materialsSlice, unitSystem(c))
 	tlist := material_views.MaterialList(
 		" | materials List",
 		fromProtected,
@@ -154,25 +222,72 @@ func HandleViewMaterialEditPage(c *fiber.Ctx) error {
 		return flash.WithError(c, fm).Redirect("/material/list")
 	}
 
+	system := unitSystem(c)
+
 	if c.Method() == "POST" {
 		material.Name = strings.Trim(c.FormValue("title"), " ")
 		material.Description = strings.Trim(c.FormValue("description"), " ")
 
 		valueStr := c.FormValue("lambda") // This is a string.
-		value, err := strconv.ParseFloat(valueStr, 64)
+		value, err := system.Parse(units.Conductivity, valueStr)
 		if err != nil {
 			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
 			return flash.WithError(c, fm).Redirect("/material/list")
 		}
 		material.Lambda = float64(value)
 
-		valuePriceStr := c.FormValue("price") // This is a string.
-		value, err = strconv.ParseFloat(valuePriceStr, 64)
+		err = parsePrice(c, system, material)
 		if err != nil {
-			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+			fm["message"] = err.Error()
+			return flash.WithError(c, fm).Redirect("/material/list")
+		}
+
+		material.FireClass, err = parseFireClass(c)
+		if err != nil {
+			fm["message"] = err.Error()
 			return flash.WithError(c, fm).Redirect("/material/list")
 		}
-		material.Price = float64(value)
+
+		material.SpecificHeat, err = system.Parse(units.SpecificHeat, c.FormValue("specific-heat", "0"))
+		if err != nil || material.SpecificHeat < 0 {
+			fm["message"] = "invalid specific heat capacity"
+			return flash.WithError(c, fm).Redirect("/material/list")
+		}
+
+		value, err = strconv.ParseFloat(c.FormValue("mu"), 64)
+		if err != nil || value < 1 {
+			fm["message"] = "invalid vapour diffusion resistance factor"
+			return flash.WithError(c, fm).Redirect("/material/list")
+		}
+		material.Mu = value
+
+		material.Thicknesses, err = parseThicknesses(c.FormValue("thicknesses"), system)
+		if err != nil {
+			fm["message"] = err.Error()
+			return flash.WithError(c, fm).Redirect("/material/list")
+		}
+
+		material.Density, material.GWP, material.GWPBasis, err = parseEmbodiedCarbon(c, system)
+		if err != nil {
+			fm["message"] = err.Error()
+			return flash.WithError(c, fm).Redirect("/material/list")
+		}
+
+		material.Type, err = parseMaterialType(c)
+		if err != nil {
+			fm["message"] = err.Error()
+			return flash.WithError(c, fm).Redirect("/material/list")
+		}
+
+		conversion, err := parseConversionFactors(c)
+		if err != nil {
+			fm["message"] = err.Error()
+			return flash.WithError(c, fm).Redirect("/material/list")
+		}
+		material.TemperatureCoefficient = conversion[0]
+		material.MoistureCoefficient = conversion[1]
+		material.MoistureContent = conversion[2]
+		material.AgeingFactor = conversion[3]
 
 		_, err = material.UpdateMaterial()
 		if err != nil {
@@ -189,7 +304,7 @@ func HandleViewMaterialEditPage(c *fiber.Ctx) error {
 		return flash.WithSuccess(c, fm).Redirect("/material/list")
 	}
 
-	uindex := material_views.UpdateIndex(recoveredMaterial)
+	uindex := material_views.UpdateIndex(recoveredMaterial, system)
 	update := material_views.Update(
 		fmt.Sprintf(" | Edit Material #%d", recoveredMaterial.ID),
 		fromProtected,
@@ -211,11 +326,13 @@ func HandleViewMaterialSearch(c *fiber.Ctx) error {
 		"type": "error",
 	}
 
+	system := unitSystem(c)
+
 	if c.Method() == "POST" {
 		search.Name = strings.Trim(c.FormValue("name"), " ")
 
 		valueStr := c.FormValue("lambda") // This is a string.
-		value, err := strconv.ParseFloat(valueStr, 64)
+		value, err := system.Parse(units.Conductivity, valueStr)
 		if err != nil {
 			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
 			return flash.WithError(c, fm).Redirect("/material/list")
@@ -223,7 +340,7 @@ func HandleViewMaterialSearch(c *fiber.Ctx) error {
 		search.Lambda = float64(value)
 
 		valuePriceStr := c.FormValue("price") // This is a string.
-		value, err = strconv.ParseFloat(valuePriceStr, 64)
+		value, err = system.Parse(units.PerVolume, valuePriceStr)
 		if err != nil {
 			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
 			return flash.WithError(c, fm).Redirect("/material/list")
@@ -239,7 +356,7 @@ func HandleViewMaterialSearch(c *fiber.Ctx) error {
 			return flash.WithError(c, fm).Redirect("/material/list")
 		}
 
-		tindex := material_views.MaterialIndex(materialsSlice)
+		tindex := material_views.MaterialIndex(materialsSlice, unitSystem(c))
 		tlist := material_views.MaterialList(
 			" | materials List",
 			fromProtected,
@@ -287,100 +404,134 @@ func HandleDeleteMaterial(c *fiber.Ctx) error {
 	return flash.WithSuccess(c, fm).Redirect("/material/list", fiber.StatusSeeOther)
 }
 
-// HandleInsulationCalculatorPage renders the insulation calculator page
-// func HandleInsulationCalculatorPage(c *fiber.Ctx) error {
-// 	materials, err := models.LoadMaterialsFromTOML("./assets/data/materials.toml")
-// 	if err != nil {
-// 		return c.Status(fiber.StatusInternalServerError).SendString("Error loading materials: " + err.Error())
-// 	}
-// 	// no clue how to finish this // TODO
-// 	return adaptor.HTTPHandlerFunc(material_views.InsulationCalculatorPage(materials))()
-// }
-
-func HandleInsulationCalculatorPage(c *fiber.Ctx) error {
-	materials, err := models.LoadMaterialsFromTOML("./assets/data/materials.toml")
+// parseThicknesses reads a comma separated list of board thicknesses, typed
+// in the unit system of the user and returned in mm. An empty list means the
+// material has no board catalogue.
+func parseThicknesses(value string, system units.System) ([]float64, error) {
+	thicknesses := []float64{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		thickness, err := system.Parse(units.Thickness, field)
+		if err != nil || thickness <= 0 {
+			return nil, fmt.Errorf("invalid board thickness %q", field)
+		}
+		thicknesses = append(thicknesses, thickness)
+	}
+
+	return thicknesses, nil
+}
+
+// parseEmbodiedCarbon reads the density and the A1–A3 GWP of the material
+// forms, converted to metric. Both are optional and default to 0.
+func parseEmbodiedCarbon(c *fiber.Ctx, system units.System) (float64, float64, models.GWPBasis, error) {
+	density, err := system.Parse(units.Density, c.FormValue("density", "0"))
+	if err != nil || density < 0 {
+		return 0, 0, "", errors.New("invalid density")
+	}
+
+	gwp, err := strconv.ParseFloat(c.FormValue("gwp", "0"), 64)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Error loading materials: " + err.Error())
+		return 0, 0, "", errors.New("invalid GWP value")
 	}
 
-	handler := adaptor.HTTPHandler(templ.Handler(material_views.InsulationCalculatorPage(materials)))
+	basis := models.GWPBasis(c.FormValue("gwp-basis", string(models.GWPPerKilogram)))
+	if basis != models.GWPPerKilogram && basis != models.GWPPerCubicMetre {
+		return 0, 0, "", errors.New("unknown GWP basis")
+	}
+	if basis == models.GWPPerCubicMetre {
+		gwp = system.ToMetric(units.PerVolume, gwp)
+	}
+	if basis == models.GWPPerKilogram && gwp != 0 && density == 0 {
+		return 0, 0, "", errors.New("a GWP per kg needs the density of the material")
+	}
 
-	return handler(c)
+	return density, gwp, basis, nil
 }
 
-// HandleCalculateInsulation handles the insulation calculation request
-func HandleCalculateInsulation(c *fiber.Ctx) error {
-	// Parse input parameters
-	wallTypeID := c.FormValue("wall-type")
-	desiredUValue, err := strconv.ParseFloat(c.FormValue("desired-u-value"), 64)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid desired U-value")
+// parsePrice reads the price of the material forms with its basis and
+// currency. Prices per m² and per package need the thickness they are quoted
+// at, and package prices the area one package covers.
+func parsePrice(c *fiber.Ctx, system units.System, material *models.Material) error {
+	basis := models.PriceBasis(c.FormValue("price-basis", string(models.PricePerCubicMetre)))
+	if basis != models.PricePerSquareMetre && basis != models.PricePerCubicMetre && basis != models.PricePerPackage {
+		return errors.New("unknown price basis")
 	}
 
-	// Get selected materials
-	materialIDs := c.FormValue("insulation-materials")
-	materialIDSlice := strings.Split(materialIDs, ",")
-	materials, err := models.GetMaterialsByIDs(materialIDSlice)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Error fetching materials: " + err.Error())
+	price, err := strconv.ParseFloat(c.FormValue("price"), 64)
+	if err != nil || price < 0 {
+		return errors.New("invalid price value")
+	}
+	if quantity, ok := basis.Quantity(); ok {
+		price = system.ToMetric(quantity, price)
 	}
 
-	// Get wall material
-	wallMaterial, err := models.GetMaterialsByIDs([]string{wallTypeID})
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Error fetching wall material: " + err.Error())
+	currency := strings.ToUpper(strings.TrimSpace(c.FormValue("currency", models.DefaultCurrency)))
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return errors.New("the currency must be a three letter ISO 4217 code")
+	}
+
+	priceThickness, packageCoverage := 0.0, 0.0
+	if basis != models.PricePerCubicMetre {
+		priceThickness, err = system.Parse(units.Thickness, c.FormValue("price-thickness"))
+		if err != nil || priceThickness <= 0 {
+			return errors.New("a price per area or package needs the thickness it is quoted at")
+		}
+	}
+	if basis == models.PricePerPackage {
+		packageCoverage, err = system.Parse(units.Area, c.FormValue("package-coverage"))
+		if err != nil || packageCoverage <= 0 {
+			return errors.New("a price per package needs the area one package covers")
+		}
 	}
 
-	// Perform optimization
-	result := optimizeInsulation(wallMaterial[0], desiredUValue, materials)
+	material.Price = price
+	material.PriceBasis = basis
+	material.PriceThickness = priceThickness
+	material.PackageCoverage = packageCoverage
+	material.Currency = currency
 
-	// Render the result using the templ component
-	return material_views.InsulationResult(result).Render(c.Context(), c.Response().BodyWriter())
+	return nil
 }
 
-func optimizeInsulation(material models.Material, desiredUValue float64, materials []models.Material) models.InsulationResult {
-	result := models.InsulationResult{
-		Layers:      []models.InsulationLayer{},
-		TotalUValue: math.Inf(1),
-		TotalCost:   0,
+// parseFireClass reads the Euroclass of the material forms, empty when the
+// material is not classified
+func parseFireClass(c *fiber.Ctx) (models.FireClass, error) {
+	class := models.FireClass(c.FormValue("fire-class"))
+	if class != "" && !class.Valid() {
+		return "", errors.New("unknown fire class")
 	}
 
-	// Sort materials by their insulation efficiency (lambda)
-	sort.Slice(materials, func(i, j int) bool {
-		return materials[i].Lambda < materials[j].Lambda
-	})
-
-	currentUValue := 0.0
-	for currentUValue < desiredUValue {
-		bestMaterial := materials[0]
-		bestThickness := 0.0
-		bestCost := math.Inf(1)
-
-		for _, material := range materials {
-			thickness := calculateRequiredThickness(material.Lambda, desiredUValue-currentUValue)
-			cost := thickness * material.Price / 1000 // Assuming price is per m³ and thickness is in mm
-
-			if cost < bestCost {
-				bestMaterial = material
-				bestThickness = thickness
-				bestCost = cost
-			}
-		}
+	return class, nil
+}
 
-		layer := models.InsulationLayer{
-			Material:  bestMaterial,
-			Thickness: bestThickness,
-			UValue:    1 / (bestThickness / 1000 / bestMaterial.Lambda),
+// parseMaterialType reads the type of a material, insulation by default
+func parseMaterialType(c *fiber.Ctx) (string, error) {
+	materialType := c.FormValue("type", models.MaterialInsulation)
+	for _, known := range models.MaterialTypes {
+		if materialType == known {
+			return materialType, nil
 		}
-		result.Layers = append(result.Layers, layer)
-		result.TotalCost += bestCost
-		currentUValue += layer.UValue
 	}
 
-	result.TotalUValue = currentUValue
-	return result
+	return "", errors.New("unknown material type")
 }
 
-func calculateRequiredThickness(lambda, targetUValue float64) float64 {
-	return 1 / (targetUValue * lambda) * 1000 // Convert to mm
+// parseConversionFactors reads the ISO 10456 coefficients fT, fψ, the
+// moisture content ψ and the ageing factor Fa, all optional and 0 by default
+func parseConversionFactors(c *fiber.Ctx) ([4]float64, error) {
+	fields := []string{"temperature-coefficient", "moisture-coefficient", "moisture-content", "ageing-factor"}
+	values := [4]float64{}
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(c.FormValue(field, "0"), 64)
+		if err != nil || value < 0 {
+			return values, fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+		}
+		values[i] = value
+	}
+
+	return values, nil
 }
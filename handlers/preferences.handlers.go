@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/units"
+	"github.com/kaloszer/insulationCalcHtmx/views/user_views"
+	"github.com/sujit-baniya/flash"
+)
+
+/********** Handlers for User Preferences **********/
+
+// unitSystem returns the unit system of the logged in user, set by
+// AuthMiddleware
+func unitSystem(c *fiber.Ctx) units.System {
+	system, ok := c.Locals("units").(units.System)
+	if !ok {
+		return units.Metric
+	}
+
+	return system
+}
+
+// Render the preferences page and save the chosen unit system
+func HandleViewPreferencesPage(c *fiber.Ctx) error {
+	if c.Method() == "POST" {
+		fm := fiber.Map{
+			"type": "error",
+		}
+
+		system, err := units.ParseSystem(c.FormValue("units"))
+		if err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect("/user/preferences")
+		}
+
+		if err := models.SetUnitSystem(c.Locals("userId").(uint64), system); err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect("/user/preferences")
+		}
+
+		fm = fiber.Map{
+			"type":    "success",
+			"message": "Unit preference saved!!",
+		}
+
+		return flash.WithSuccess(c, fm).Redirect("/user/preferences")
+	}
+
+	pindex := user_views.PreferencesIndex(unitSystem(c))
+	preferences := user_views.Preferences(
+		" | Preferences",
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		pindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(preferences))
+
+	return handler(c)
+}
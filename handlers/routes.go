@@ -47,7 +47,47 @@ func Setup(app *fiber.App) {
 	materialApp.Post("/edit/:id", HandleViewMaterialEditPage)
 	materialApp.Delete("/delete/:id", HandleDeleteMaterial)
 	materialApp.Get("/insulation-calculator", HandleInsulationCalculatorPage)
+	materialApp.Post("/base-layers/:action/:index?", HandleBaseLayers)
 	materialApp.Post("/calculate-insulation", HandleCalculateInsulation)
+	materialApp.Post("/calculate-insulation/solution", HandleCalculateSolution)
+	materialApp.Post("/calculate-insulation/simulation.csv", HandleSimulationCSV)
+	materialApp.Get("/thermal-bridge", HandleThermalBridgePage)
+	materialApp.Get("/thermal-bridge/example", HandleThermalBridgeExample)
+	materialApp.Post("/thermal-bridge", HandleSolveThermalBridge)
+
+	buildingApp := app.Group("/building", AuthMiddleware)
+	buildingApp.Get("/list", HandleBuildingViewList)
+	buildingApp.Get("/create", HandleViewBuildingCreatePage)
+	buildingApp.Post("/create", HandleViewBuildingCreatePage)
+	buildingApp.Get("/edit/:id", HandleViewBuildingEditPage)
+	buildingApp.Post("/edit/:id", HandleViewBuildingEditPage)
+	buildingApp.Delete("/delete/:id", HandleDeleteBuilding)
+	buildingApp.Get("/:id", HandleViewBuildingSummary)
+	buildingApp.Get("/:id/element/create", HandleViewElementCreatePage)
+	buildingApp.Post("/:id/element/create", HandleViewElementCreatePage)
+	buildingApp.Get("/:id/element/edit/:elementId", HandleViewElementEditPage)
+	buildingApp.Post("/:id/element/edit/:elementId", HandleViewElementEditPage)
+	buildingApp.Delete("/:id/element/delete/:elementId", HandleDeleteElement)
+
+	windowApp := app.Group("/window", AuthMiddleware)
+	windowApp.Get("/list", HandleWindowViewList)
+	windowApp.Get("/create", HandleViewWindowCreatePage)
+	windowApp.Post("/create", HandleViewWindowCreatePage)
+	windowApp.Get("/edit/:id", HandleViewWindowEditPage)
+	windowApp.Post("/edit/:id", HandleViewWindowEditPage)
+	windowApp.Delete("/delete/:id", HandleDeleteWindow)
+
+	junctionApp := app.Group("/junction", AuthMiddleware)
+	junctionApp.Get("/list", HandleJunctionViewList)
+	junctionApp.Get("/create", HandleViewJunctionCreatePage)
+	junctionApp.Post("/create", HandleViewJunctionCreatePage)
+	junctionApp.Get("/edit/:id", HandleViewJunctionEditPage)
+	junctionApp.Post("/edit/:id", HandleViewJunctionEditPage)
+	junctionApp.Delete("/delete/:id", HandleDeleteJunction)
+
+	userApp := app.Group("/user", AuthMiddleware)
+	userApp.Get("/preferences", HandleViewPreferencesPage)
+	userApp.Post("/preferences", HandleViewPreferencesPage)
 
 	/* Page Not Found Management */
 	app.Use(func(c *fiber.Ctx) error {
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/kaloszer/insulationCalcHtmx/calculations"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/views/material_views"
+)
+
+const thermalBridgesDir = "./assets/data/thermal_bridges"
+
+// HandleThermalBridgePage renders the 2D thermal bridge solver with the
+// first bundled geometry
+func HandleThermalBridgePage(c *fiber.Ctx) error {
+	examples, err := models.LoadBridgeExamples(thermalBridgesDir)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading thermal bridge examples: " + err.Error())
+	}
+
+	materials, err := models.GetAllMaterials()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading materials: " + err.Error())
+	}
+
+	handler := adaptor.HTTPHandler(templ.Handler(material_views.ThermalBridgePage(examples, materials)))
+
+	return handler(c)
+}
+
+// HandleThermalBridgeExample swaps a bundled geometry into the editor
+func HandleThermalBridgeExample(c *fiber.Ctx) error {
+	examples, err := models.LoadBridgeExamples(thermalBridgesDir)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading thermal bridge examples: " + err.Error())
+	}
+
+	for _, example := range examples {
+		if example.File == c.Query("example") {
+			handler := adaptor.HTTPHandler(templ.Handler(material_views.GeometryEditor(example.Contents)))
+
+			return handler(c)
+		}
+	}
+
+	return c.Status(fiber.StatusNotFound).SendString("Unknown thermal bridge example")
+}
+
+// HandleSolveThermalBridge solves the geometry of the editor and renders
+// the temperature field with the linear thermal transmittance
+func HandleSolveThermalBridge(c *fiber.Ctx) error {
+	geometry, err := models.ParseBridgeGeometry(c.FormValue("geometry"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	materials, err := models.GetAllMaterials()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error loading materials: " + err.Error())
+	}
+
+	result, err := calculations.SolveThermalBridge(geometry, materials)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	handler := adaptor.HTTPHandler(templ.Handler(material_views.ThermalBridgeResult(result, unitSystem(c))))
+
+	return handler(c)
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/units"
+	"github.com/kaloszer/insulationCalcHtmx/views/window_views"
+	"github.com/sujit-baniya/flash"
+)
+
+/********** Handlers for Window Views **********/
+
+// Render the window and door library of the user
+func HandleWindowViewList(c *fiber.Ctx) error {
+	window := new(models.WindowProduct)
+	window.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	windows, err := window.GetAllWindows()
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/window/create")
+	}
+
+	windex := window_views.WindowIndex(windows, unitSystem(c))
+	wlist := window_views.WindowList(
+		" | Windows and Doors",
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		windex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(wlist))
+
+	return handler(c)
+}
+
+// Render Create Window Page with success/error messages
+func HandleViewWindowCreatePage(c *fiber.Ctx) error {
+	window := models.WindowProduct{
+		CreatedBy:  c.Locals("userId").(uint64),
+		Kind:       models.WindowKindWindow,
+		Width:      1230,
+		Height:     1480,
+		FrameWidth: 110,
+		Ug:         0.6,
+		Uf:         1.0,
+		PsiG:       0.04,
+		GValue:     0.5,
+	}
+
+	if c.Method() == "POST" {
+		fm := fiber.Map{
+			"type": "error",
+		}
+
+		if err := parseWindowForm(c, &window); err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect("/window/create")
+		}
+
+		if err := window.CreateWindow(); err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect("/window/list")
+		}
+
+		return flash.WithSuccess(c, fiber.Map{
+			"type":    "success",
+			"message": "Window successfully created!!",
+		}).Redirect("/window/list")
+	}
+
+	cindex := window_views.WindowFormIndex("New window or door", window, unitSystem(c))
+	create := window_views.WindowForm(
+		" | Create Window",
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		cindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(create))
+
+	return handler(c)
+}
+
+// Render Edit Window Page with success/error messages
+func HandleViewWindowEditPage(c *fiber.Ctx) error {
+	idParams, _ := strconv.Atoi(c.Params("id"))
+
+	window := new(models.WindowProduct)
+	window.ID = uint64(idParams)
+	window.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	recovered, err := window.GetWindowById()
+	if err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/window/list")
+	}
+
+	if c.Method() == "POST" {
+		if err := parseWindowForm(c, &recovered); err != nil {
+			fm["message"] = err.Error()
+
+			return flash.WithError(c, fm).Redirect("/window/list")
+		}
+
+		if err := recovered.UpdateWindow(); err != nil {
+			fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+			return flash.WithError(c, fm).Redirect("/window/list")
+		}
+
+		fm = fiber.Map{
+			"type":    "success",
+			"message": "Window successfully updated!!",
+		}
+
+		return flash.WithSuccess(c, fm).Redirect("/window/list")
+	}
+
+	uindex := window_views.WindowFormIndex(fmt.Sprintf("Edit Window #%d", recovered.ID), recovered, unitSystem(c))
+	update := window_views.WindowForm(
+		fmt.Sprintf(" | Edit Window #%d", recovered.ID),
+		fromProtected,
+		flash.Get(c),
+		c.Locals("username").(string),
+		uindex,
+	)
+
+	handler := adaptor.HTTPHandler(templ.Handler(update))
+
+	return handler(c)
+}
+
+// Handler Remove Window
+func HandleDeleteWindow(c *fiber.Ctx) error {
+	idParams, _ := strconv.Atoi(c.Params("id"))
+
+	window := new(models.WindowProduct)
+	window.ID = uint64(idParams)
+	window.CreatedBy = c.Locals("userId").(uint64)
+
+	fm := fiber.Map{
+		"type": "error",
+	}
+
+	if err := window.DeleteWindow(); err != nil {
+		fm["message"] = fmt.Sprintf("something went wrong: %s", err)
+
+		return flash.WithError(c, fm).Redirect("/window/list", fiber.StatusSeeOther)
+	}
+
+	fm = fiber.Map{
+		"type":    "success",
+		"message": "Window successfully deleted!!",
+	}
+
+	return flash.WithSuccess(c, fm).Redirect("/window/list", fiber.StatusSeeOther)
+}
+
+// parseWindowForm reads the window form into window, sizes and U-values in
+// the unit system of the user
+func parseWindowForm(c *fiber.Ctx, window *models.WindowProduct) error {
+	window.Name = strings.Trim(c.FormValue("name"), " ")
+	window.Description = strings.Trim(c.FormValue("description"), " ")
+	if len(window.Name) < 3 {
+		return errors.New("the window name needs at least 3 characters")
+	}
+
+	window.Kind = models.WindowKind(c.FormValue("kind"))
+	if window.Kind != models.WindowKindWindow && window.Kind != models.WindowKindDoor {
+		return errors.New("unknown window kind")
+	}
+
+	system := unitSystem(c)
+	fields := []string{"width", "height", "frame-width", "ug", "uf", "psi-g"}
+	quantities := []units.Quantity{units.Thickness, units.Thickness, units.Thickness, units.UValue, units.UValue, units.LinearTransmittance}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := system.Parse(quantities[i], c.FormValue(field))
+		if err != nil || value < 0 {
+			return fmt.Errorf("invalid %s", strings.ReplaceAll(field, "-", " "))
+		}
+		values[i] = value
+	}
+
+	window.Width, window.Height, window.FrameWidth = values[0], values[1], values[2]
+	window.Ug, window.Uf, window.PsiG = values[3], values[4], values[5]
+
+	gValue, err := strconv.ParseFloat(c.FormValue("g-value"), 64)
+	if err != nil || gValue < 0 {
+		return errors.New("invalid g value")
+	}
+	window.GValue = gValue
+
+	if window.Width <= 0 || window.Height <= 0 {
+		return errors.New("the window needs a width and a height")
+	}
+	if 2*window.FrameWidth >= window.Width || 2*window.FrameWidth >= window.Height {
+		return errors.New("the frame is wider than the window")
+	}
+	if window.GValue > 1 {
+		return errors.New("the g-value must be between 0 and 1")
+	}
+
+	return nil
+}
@@ -0,0 +1,771 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ElementType is the kind of envelope element, it selects the heat-flow
+// direction of its construction
+type ElementType string
+
+const (
+	ElementWall        ElementType = "wall"
+	ElementRoof        ElementType = "roof"
+	ElementFloor       ElementType = "floor"        // over unheated space or outside air
+	ElementGroundFloor ElementType = "ground-floor" // slab on the ground, ISO 13370
+	ElementWindow      ElementType = "window"
+	ElementDoor        ElementType = "door"
+)
+
+// ElementTypes lists the element types in the order they are shown
+var ElementTypes = []ElementType{ElementWall, ElementRoof, ElementFloor, ElementGroundFloor, ElementWindow, ElementDoor}
+
+// LayeredElementTypes are the element types built up from material layers,
+// the ones the insulation calculator works on
+var LayeredElementTypes = []ElementType{ElementWall, ElementRoof, ElementFloor, ElementGroundFloor}
+
+// HeatFlow returns the direction of the heat flow through the element in
+// winter
+func (t ElementType) HeatFlow() HeatFlowDirection {
+	switch t {
+	case ElementRoof:
+		return HeatFlowUpward
+	case ElementFloor, ElementGroundFloor:
+		return HeatFlowDownward
+	default:
+		return HeatFlowHorizontal
+	}
+}
+
+// Orientations are the compass directions a vertical element can face
+var Orientations = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// Building is a heated volume described by its envelope elements
+type Building struct {
+	ID                     uint64            `json:"id"`
+	CreatedBy              uint64            `json:"created_by"`
+	Name                   string            `json:"name"`
+	Description            string            `json:"description,omitempty"`
+	IndoorTemperature      float64           `json:"indoor_temperature"`       // °C
+	OutdoorTemperature     float64           `json:"outdoor_temperature"`      // design, °C
+	ThermalBridgeAllowance float64           `json:"thermal_bridge_allowance"` // ΔU_TB over the envelope area, W/m²K
+	Energy                 *EnergyInputs     `json:"energy,omitempty"`         // for the monthly heating demand
+	Elements               []BuildingElement `json:"elements"`
+}
+
+// BuildingElement is one part of the envelope with its own construction.
+// Windows and doors can use a product of the window library instead, other
+// elements without layers use the U-value entered directly.
+type BuildingElement struct {
+	ID          uint64            `json:"id"`
+	BuildingID  uint64            `json:"building_id"`
+	Name        string            `json:"name"`
+	Type        ElementType       `json:"type"`
+	Orientation string            `json:"orientation,omitempty"`
+	Area        float64           `json:"area"`    // m²
+	UValue      float64           `json:"u_value"` // W/m²K, used when there are no layers
+	Layers      []InsulationLayer `json:"layers"`  // interior to exterior
+	Ground      *GroundFloor      `json:"ground,omitempty"`
+	Window      *PlacedWindow     `json:"window,omitempty"`
+	Junctions   []PlacedJunction  `json:"junctions,omitempty"` // linear thermal bridges along the element
+
+	Fixings      *Fixings      `json:"fixings,omitempty"`
+	InvertedRoof *InvertedRoof `json:"inverted_roof,omitempty"`
+}
+
+// PlacedWindow places a number of identical window products in an element
+type PlacedWindow struct {
+	Product WindowProduct `json:"product"`
+	Count   int           `json:"count"`
+}
+
+// Construction returns the layers of the element as a construction
+func (e BuildingElement) Construction() Construction {
+	construction := Construction{
+		Layers:       e.Layers,
+		HeatFlow:     e.Type.HeatFlow(),
+		Fixings:      e.Fixings,
+		InvertedRoof: e.InvertedRoof,
+	}
+	if e.Type == ElementGroundFloor {
+		construction.Ground = e.Ground
+	}
+
+	return construction
+}
+
+// ElementHeatLoss is the share of one element in the transmission heat loss
+type ElementHeatLoss struct {
+	Element      BuildingElement `json:"element"`
+	UValue       float64         `json:"u_value"`       // W/m²K
+	HeatTransfer float64         `json:"heat_transfer"` // A·U, W/K
+	Junctions    float64         `json:"junctions"`     // Σ ψ·l along the element, W/K
+}
+
+// EffectiveUValue spreads the junctions of the element over its area,
+// U + Σ ψ·l / A
+func (l ElementHeatLoss) EffectiveUValue() float64 {
+	if l.Element.Area <= 0 {
+		return l.UValue
+	}
+	return l.UValue + l.Junctions/l.Element.Area
+}
+
+// HeatLoss sums the transmission heat loss of a building
+type HeatLoss struct {
+	Elements              []ElementHeatLoss `json:"elements"`
+	Area                  float64           `json:"area"`            // envelope area, m²
+	Transmission          float64           `json:"transmission"`    // Σ A·U, W/K
+	AllowanceArea         float64           `json:"allowance_area"`  // Σ A of the elements without junctions, m²
+	ThermalBridges        float64           `json:"thermal_bridges"` // ΔU_TB · Σ A over AllowanceArea, W/K
+	Junctions             float64           `json:"junctions"`       // Σ ψ·l, W/K
+	HT                    float64           `json:"h_t"`             // W/K
+	TemperatureDifference float64           `json:"temperature_difference"`
+	DesignLoad            float64           `json:"design_load"` // H_T · ΔT, W
+}
+
+func (b *Building) GetAllBuildings() ([]Building, error) {
+	query := `SELECT id, name, description, indoor_temperature, outdoor_temperature, thermal_bridge_allowance
+		FROM buildings WHERE created_by = ? ORDER BY id DESC`
+
+	rows, err := db.Query(query, b.CreatedBy)
+	if err != nil {
+		return []Building{}, err
+	}
+	defer rows.Close()
+
+	buildings := []Building{}
+	for rows.Next() {
+		building := Building{CreatedBy: b.CreatedBy}
+		err := rows.Scan(&building.ID, &building.Name, &building.Description,
+			&building.IndoorTemperature, &building.OutdoorTemperature, &building.ThermalBridgeAllowance)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning building row: %w", err)
+		}
+		buildings = append(buildings, building)
+	}
+
+	for i := range buildings {
+		buildings[i].Elements, err = GetBuildingElements(buildings[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buildings, nil
+}
+
+// GetBuildingById loads a building of the user with its elements
+func (b *Building) GetBuildingById() (Building, error) {
+	query := `SELECT id, created_by, name, description, indoor_temperature, outdoor_temperature, thermal_bridge_allowance
+		FROM buildings WHERE created_by = ? AND id = ?`
+
+	var building Building
+	err := db.QueryRow(query, b.CreatedBy, b.ID).Scan(
+		&building.ID,
+		&building.CreatedBy,
+		&building.Name,
+		&building.Description,
+		&building.IndoorTemperature,
+		&building.OutdoorTemperature,
+		&building.ThermalBridgeAllowance,
+	)
+	if err != nil {
+		return Building{}, err
+	}
+
+	building.Elements, err = GetBuildingElements(building.ID)
+	if err != nil {
+		return Building{}, err
+	}
+
+	building.Energy, err = getBuildingEnergy(building.ID)
+	if err != nil {
+		return Building{}, err
+	}
+
+	return building, nil
+}
+
+func (b *Building) CreateBuilding() (Building, error) {
+	query := `INSERT INTO buildings (created_by, name, description, indoor_temperature, outdoor_temperature, thermal_bridge_allowance)
+		VALUES(?, ?, ?, ?, ?, ?)`
+
+	result, err := db.Exec(query, b.CreatedBy, b.Name, b.Description,
+		b.IndoorTemperature, b.OutdoorTemperature, b.ThermalBridgeAllowance)
+	if err != nil {
+		return Building{}, fmt.Errorf("error adding building: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Building{}, fmt.Errorf("error adding building: %w", err)
+	}
+
+	created := *b
+	created.ID = uint64(id)
+
+	if err := setBuildingEnergy(created.ID, b.Energy); err != nil {
+		return Building{}, err
+	}
+
+	return created, nil
+}
+
+func (b *Building) UpdateBuilding() error {
+	query := `UPDATE buildings SET name = ?, description = ?, indoor_temperature = ?, outdoor_temperature = ?, thermal_bridge_allowance = ?
+		WHERE created_by = ? AND id = ?`
+
+	result, err := db.Exec(query, b.Name, b.Description, b.IndoorTemperature,
+		b.OutdoorTemperature, b.ThermalBridgeAllowance, b.CreatedBy, b.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	return setBuildingEnergy(b.ID, b.Energy)
+}
+
+// DeleteBuilding removes a building together with its elements
+func (b *Building) DeleteBuilding() error {
+	building, err := b.GetBuildingById()
+	if err != nil {
+		return err
+	}
+
+	for _, element := range building.Elements {
+		if err := element.DeleteElement(); err != nil {
+			return err
+		}
+	}
+	if err := setBuildingEnergy(building.ID, nil); err != nil {
+		return err
+	}
+
+	result, err := db.Exec(`DELETE FROM buildings WHERE created_by = ? AND id = ?`, b.CreatedBy, b.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	return nil
+}
+
+// GetBuildingElements returns the elements of a building with their layers
+func GetBuildingElements(buildingID uint64) ([]BuildingElement, error) {
+	query := `SELECT id, building_id, name, type, orientation, area, u_value
+		FROM building_elements WHERE building_id = ? ORDER BY id`
+
+	rows, err := db.Query(query, buildingID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying building elements: %w", err)
+	}
+	defer rows.Close()
+
+	elements := []BuildingElement{}
+	for rows.Next() {
+		var e BuildingElement
+		err := rows.Scan(&e.ID, &e.BuildingID, &e.Name, &e.Type, &e.Orientation, &e.Area, &e.UValue)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning building element row: %w", err)
+		}
+		elements = append(elements, e)
+	}
+
+	for i := range elements {
+		elements[i].Layers, err = getElementLayers(elements[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		elements[i].Ground, err = getElementGround(elements[i])
+		if err != nil {
+			return nil, err
+		}
+		elements[i].Window, err = getElementWindow(elements[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		elements[i].Fixings, elements[i].InvertedRoof, err = getElementCorrections(elements[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		elements[i].Junctions, err = getElementJunctions(elements[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return elements, nil
+}
+
+// GetElementById returns an element of the given building
+func (e *BuildingElement) GetElementById() (BuildingElement, error) {
+	query := `SELECT id, building_id, name, type, orientation, area, u_value
+		FROM building_elements WHERE building_id = ? AND id = ?`
+
+	var element BuildingElement
+	err := db.QueryRow(query, e.BuildingID, e.ID).Scan(
+		&element.ID,
+		&element.BuildingID,
+		&element.Name,
+		&element.Type,
+		&element.Orientation,
+		&element.Area,
+		&element.UValue,
+	)
+	if err != nil {
+		return BuildingElement{}, err
+	}
+
+	element.Layers, err = getElementLayers(element.ID)
+	if err != nil {
+		return BuildingElement{}, err
+	}
+
+	element.Ground, err = getElementGround(element)
+	if err != nil {
+		return BuildingElement{}, err
+	}
+
+	element.Window, err = getElementWindow(element.ID)
+	if err != nil {
+		return BuildingElement{}, err
+	}
+
+	element.Fixings, element.InvertedRoof, err = getElementCorrections(element.ID)
+	if err != nil {
+		return BuildingElement{}, err
+	}
+
+	element.Junctions, err = getElementJunctions(element.ID)
+	if err != nil {
+		return BuildingElement{}, err
+	}
+
+	return element, nil
+}
+
+func (e *BuildingElement) CreateElement() error {
+	query := `INSERT INTO building_elements (building_id, name, type, orientation, area, u_value)
+		VALUES(?, ?, ?, ?, ?, ?)`
+
+	result, err := db.Exec(query, e.BuildingID, e.Name, e.Type, e.Orientation, e.Area, e.UValue)
+	if err != nil {
+		return fmt.Errorf("error adding building element: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error adding building element: %w", err)
+	}
+	e.ID = uint64(id)
+
+	if err := setElementLayers(e.ID, e.Layers); err != nil {
+		return err
+	}
+
+	if err := setElementGround(e.ID, e.Ground); err != nil {
+		return err
+	}
+
+	if err := setElementCorrections(e.ID, e.Fixings, e.InvertedRoof); err != nil {
+		return err
+	}
+
+	if err := setElementJunctions(e.ID, e.Junctions); err != nil {
+		return err
+	}
+
+	return setElementWindow(e.ID, e.Window)
+}
+
+func (e *BuildingElement) UpdateElement() error {
+	query := `UPDATE building_elements SET name = ?, type = ?, orientation = ?, area = ?, u_value = ?
+		WHERE building_id = ? AND id = ?`
+
+	result, err := db.Exec(query, e.Name, e.Type, e.Orientation, e.Area, e.UValue, e.BuildingID, e.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	if err := setElementLayers(e.ID, e.Layers); err != nil {
+		return err
+	}
+
+	if err := setElementGround(e.ID, e.Ground); err != nil {
+		return err
+	}
+
+	if err := setElementCorrections(e.ID, e.Fixings, e.InvertedRoof); err != nil {
+		return err
+	}
+
+	if err := setElementJunctions(e.ID, e.Junctions); err != nil {
+		return err
+	}
+
+	return setElementWindow(e.ID, e.Window)
+}
+
+// elementTables hold the data of an element by element_id
+var elementTables = []string{
+	"building_element_layers",
+	"building_element_layer_bridges",
+	"building_element_air_layers",
+	"element_fixings",
+	"inverted_roofs",
+	"ground_floors",
+	"element_windows",
+	"element_junctions",
+}
+
+// DeleteElement removes an element of the building together with its data in
+// one transaction. Nothing is touched unless the element belongs to the
+// building.
+func (e *BuildingElement) DeleteElement() error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM building_elements WHERE building_id = ? AND id = ?`, e.BuildingID, e.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	for _, table := range elementTables {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE element_id = ?`, e.ID); err != nil {
+			return fmt.Errorf("error clearing %s: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// getElementLayers loads the layers of an element, interior first
+func getElementLayers(elementID uint64) ([]InsulationLayer, error) {
+	rows, err := db.Query(`SELECT material_id, thickness FROM building_element_layers
+		WHERE element_id = ? ORDER BY position`, elementID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying element layers: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	thicknesses := []float64{}
+	for rows.Next() {
+		var id string
+		var thickness float64
+		if err := rows.Scan(&id, &thickness); err != nil {
+			return nil, fmt.Errorf("error scanning element layer row: %w", err)
+		}
+		ids = append(ids, id)
+		thicknesses = append(thicknesses, thickness)
+	}
+
+	materials, err := GetMaterialsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]Material, len(materials))
+	for _, material := range materials {
+		byID[fmt.Sprint(material.ID)] = material
+	}
+
+	air, err := getElementAirLayers(elementID)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]InsulationLayer, 0, len(ids))
+	for i, id := range ids {
+		if air[i] != nil {
+			layers = append(layers, InsulationLayer{Material: AirMaterial(), Thickness: thicknesses[i], Air: air[i]})
+			continue
+		}
+
+		material, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("element #%d uses unknown material #%s", elementID, id)
+		}
+		layers = append(layers, InsulationLayer{Material: material, Thickness: thicknesses[i]})
+	}
+
+	if err := getElementBridges(elementID, layers); err != nil {
+		return nil, err
+	}
+
+	return layers, nil
+}
+
+// getElementAirLayers loads the air layers of an element by position. Their
+// rows in building_element_layers have no material.
+func getElementAirLayers(elementID uint64) (map[int]*AirLayer, error) {
+	rows, err := db.Query(`SELECT position, heat_flow, ventilation FROM building_element_air_layers
+		WHERE element_id = ?`, elementID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying element air layers: %w", err)
+	}
+	defer rows.Close()
+
+	air := map[int]*AirLayer{}
+	for rows.Next() {
+		var position int
+		layer := &AirLayer{}
+		if err := rows.Scan(&position, &layer.HeatFlow, &layer.Ventilation); err != nil {
+			return nil, fmt.Errorf("error scanning element air layer row: %w", err)
+		}
+		air[position] = layer
+	}
+
+	return air, nil
+}
+
+// getElementBridges adds the bridging materials to the layers of an element
+func getElementBridges(elementID uint64, layers []InsulationLayer) error {
+	rows, err := db.Query(`SELECT position, material_id, fraction FROM building_element_layer_bridges
+		WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error querying element layer bridges: %w", err)
+	}
+	defer rows.Close()
+
+	positions := []int{}
+	ids := []string{}
+	fractions := []float64{}
+	for rows.Next() {
+		var position int
+		var id string
+		var fraction float64
+		if err := rows.Scan(&position, &id, &fraction); err != nil {
+			return fmt.Errorf("error scanning element layer bridge row: %w", err)
+		}
+		positions = append(positions, position)
+		ids = append(ids, id)
+		fractions = append(fractions, fraction)
+	}
+
+	materials, err := GetMaterialsByIDs(ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]Material, len(materials))
+	for _, material := range materials {
+		byID[fmt.Sprint(material.ID)] = material
+	}
+
+	for i, position := range positions {
+		material, ok := byID[ids[i]]
+		if !ok || position >= len(layers) {
+			return fmt.Errorf("element #%d has an unknown layer bridge", elementID)
+		}
+		layers[position].Bridge = &LayerBridge{Material: material, Fraction: fractions[i]}
+	}
+
+	return nil
+}
+
+// setElementLayers replaces the layers of an element
+func setElementLayers(elementID uint64, layers []InsulationLayer) error {
+	_, err := db.Exec(`DELETE FROM building_element_layers WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing element layers: %w", err)
+	}
+	_, err = db.Exec(`DELETE FROM building_element_layer_bridges WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing element layer bridges: %w", err)
+	}
+	_, err = db.Exec(`DELETE FROM building_element_air_layers WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing element air layers: %w", err)
+	}
+
+	for i, layer := range layers {
+		_, err := db.Exec(`INSERT INTO building_element_layers (element_id, position, material_id, thickness) VALUES(?, ?, ?, ?)`,
+			elementID, i, layer.Material.ID, layer.Thickness)
+		if err != nil {
+			return fmt.Errorf("error adding element layer: %w", err)
+		}
+
+		if layer.Air != nil {
+			_, err := db.Exec(`INSERT INTO building_element_air_layers (element_id, position, heat_flow, ventilation) VALUES(?, ?, ?, ?)`,
+				elementID, i, layer.Air.HeatFlow, layer.Air.Ventilation)
+			if err != nil {
+				return fmt.Errorf("error adding element air layer: %w", err)
+			}
+		}
+
+		if layer.Bridged() {
+			_, err := db.Exec(`INSERT INTO building_element_layer_bridges (element_id, position, material_id, fraction) VALUES(?, ?, ?, ?)`,
+				elementID, i, layer.Bridge.Material.ID, layer.Bridge.Fraction)
+			if err != nil {
+				return fmt.Errorf("error adding element layer bridge: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getElementCorrections loads the fixings and the inverted roof data of an
+// element, nil when it has none
+func getElementCorrections(elementID uint64) (*Fixings, *InvertedRoof, error) {
+	fixings := &Fixings{}
+	err := db.QueryRow(`SELECT count, diameter, conductivity, penetration FROM element_fixings WHERE element_id = ?`, elementID).Scan(
+		&fixings.Count,
+		&fixings.Diameter,
+		&fixings.Conductivity,
+		&fixings.Penetration,
+	)
+	if err == sql.ErrNoRows {
+		fixings = nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error loading fixings of element #%d: %w", elementID, err)
+	}
+
+	roof := &InvertedRoof{}
+	err = db.QueryRow(`SELECT precipitation, drainage FROM inverted_roofs WHERE element_id = ?`, elementID).Scan(
+		&roof.Precipitation,
+		&roof.Drainage,
+	)
+	if err == sql.ErrNoRows {
+		roof = nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error loading inverted roof of element #%d: %w", elementID, err)
+	}
+
+	return fixings, roof, nil
+}
+
+// setElementCorrections replaces the fixings and the inverted roof data of an
+// element, nil removes them
+func setElementCorrections(elementID uint64, fixings *Fixings, roof *InvertedRoof) error {
+	_, err := db.Exec(`DELETE FROM element_fixings WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing fixings: %w", err)
+	}
+	_, err = db.Exec(`DELETE FROM inverted_roofs WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing inverted roof: %w", err)
+	}
+
+	if fixings != nil {
+		_, err = db.Exec(`INSERT INTO element_fixings (element_id, count, diameter, conductivity, penetration) VALUES(?, ?, ?, ?, ?)`,
+			elementID, fixings.Count, fixings.Diameter, fixings.Conductivity, fixings.Penetration)
+		if err != nil {
+			return fmt.Errorf("error adding fixings: %w", err)
+		}
+	}
+
+	if roof != nil {
+		_, err = db.Exec(`INSERT INTO inverted_roofs (element_id, precipitation, drainage) VALUES(?, ?, ?)`,
+			elementID, roof.Precipitation, roof.Drainage)
+		if err != nil {
+			return fmt.Errorf("error adding inverted roof: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getElementGround loads the ISO 13370 data of a ground floor element
+func getElementGround(element BuildingElement) (*GroundFloor, error) {
+	if element.Type != ElementGroundFloor {
+		return nil, nil
+	}
+
+	query := `SELECT perimeter, wall_thickness, soil, edge_insulation, edge_insulation_width, edge_insulation_thickness, edge_insulation_lambda
+		FROM ground_floors WHERE element_id = ?`
+
+	ground := GroundFloor{Area: element.Area}
+	err := db.QueryRow(query, element.ID).Scan(
+		&ground.Perimeter,
+		&ground.WallThickness,
+		&ground.Soil,
+		&ground.EdgeInsulation,
+		&ground.EdgeInsulationWidth,
+		&ground.EdgeInsulationThickness,
+		&ground.EdgeInsulationLambda,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error loading ground floor of element #%d: %w", element.ID, err)
+	}
+
+	return &ground, nil
+}
+
+// setElementGround replaces the ISO 13370 data of an element, nil removes it
+func setElementGround(elementID uint64, ground *GroundFloor) error {
+	_, err := db.Exec(`DELETE FROM ground_floors WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing ground floor: %w", err)
+	}
+	if ground == nil {
+		return nil
+	}
+
+	_, err = db.Exec(`INSERT INTO ground_floors (element_id, perimeter, wall_thickness, soil, edge_insulation, edge_insulation_width, edge_insulation_thickness, edge_insulation_lambda)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		elementID, ground.Perimeter, ground.WallThickness, ground.Soil, ground.EdgeInsulation,
+		ground.EdgeInsulationWidth, ground.EdgeInsulationThickness, ground.EdgeInsulationLambda)
+	if err != nil {
+		return fmt.Errorf("error adding ground floor: %w", err)
+	}
+
+	return nil
+}
+
+// getElementWindow loads the window product placed in an element, if any
+func getElementWindow(elementID uint64) (*PlacedWindow, error) {
+	query := `SELECT w.id, w.created_by, w.name, w.description, w.kind, w.width, w.height, w.frame_width, w.ug, w.uf, w.psi_g, w.g_value, ew.count
+		FROM element_windows ew JOIN windows w ON w.id = ew.window_id WHERE ew.element_id = ?`
+
+	var window PlacedWindow
+	p := &window.Product
+	err := db.QueryRow(query, elementID).Scan(&p.ID, &p.CreatedBy, &p.Name, &p.Description, &p.Kind,
+		&p.Width, &p.Height, &p.FrameWidth, &p.Ug, &p.Uf, &p.PsiG, &p.GValue, &window.Count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading window of element #%d: %w", elementID, err)
+	}
+
+	return &window, nil
+}
+
+// setElementWindow replaces the window product of an element, nil removes it
+func setElementWindow(elementID uint64, window *PlacedWindow) error {
+	_, err := db.Exec(`DELETE FROM element_windows WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing element window: %w", err)
+	}
+	if window == nil {
+		return nil
+	}
+
+	_, err = db.Exec(`INSERT INTO element_windows (element_id, window_id, count) VALUES(?, ?, ?)`,
+		elementID, window.Product.ID, window.Count)
+	if err != nil {
+		return fmt.Errorf("error adding element window: %w", err)
+	}
+
+	return nil
+}
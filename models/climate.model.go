@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DesignConditions are the indoor and outdoor air conditions a construction
+// is checked against. Relative humidities are fractions (0.5 = 50 %).
+type DesignConditions struct {
+	IndoorTemperature  float64 `json:"indoor_temperature"`  // °C
+	IndoorHumidity     float64 `json:"indoor_humidity"`     // 0..1
+	OutdoorTemperature float64 `json:"outdoor_temperature"` // °C
+	OutdoorHumidity    float64 `json:"outdoor_humidity"`    // 0..1
+}
+
+// ClimateLocation holds monthly mean outdoor conditions, January first
+type ClimateLocation struct {
+	Name        string               `json:"name" toml:"name"`
+	Temperature []float64            `json:"temperature" toml:"temperature"`           // °C
+	Humidity    []float64            `json:"humidity" toml:"humidity"`                 // %
+	Irradiation map[string][]float64 `json:"irradiation,omitempty" toml:"irradiation"` // kWh/m² per month by orientation and "horizontal"
+}
+
+// HorizontalIrradiation is the key of the irradiation on a horizontal surface
+const HorizontalIrradiation = "horizontal"
+
+// SolarIrradiation returns the monthly irradiation on a surface facing the
+// orientation, a horizontal one for an empty orientation
+func (l ClimateLocation) SolarIrradiation(orientation string) ([]float64, bool) {
+	if orientation == "" {
+		orientation = HorizontalIrradiation
+	}
+	irradiation, ok := l.Irradiation[orientation]
+	return irradiation, ok
+}
+
+// ClimateTOMLData represents the structure of the climate TOML file
+type ClimateTOMLData struct {
+	Locations []ClimateLocation `toml:"location"`
+}
+
+// LoadClimateFromTOML loads the bundled monthly climate table
+func LoadClimateFromTOML(filename string) ([]ClimateLocation, error) {
+	var data ClimateTOMLData
+
+	if _, err := toml.DecodeFile(filename, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode climate from TOML file: %w", err)
+	}
+
+	for _, location := range data.Locations {
+		if len(location.Temperature) != 12 || len(location.Humidity) != 12 {
+			return nil, fmt.Errorf("climate location %q needs 12 monthly values", location.Name)
+		}
+		for orientation, irradiation := range location.Irradiation {
+			if len(irradiation) != 12 {
+				return nil, fmt.Errorf("climate location %q needs 12 monthly irradiation values facing %s", location.Name, orientation)
+			}
+		}
+	}
+
+	return data.Locations, nil
+}
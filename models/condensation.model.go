@@ -0,0 +1,43 @@
+package models
+
+// VapourPoint is one plane of a Glaser diagram. Planes are the layer
+// boundaries plus extra planes inside thick layers.
+type VapourPoint struct {
+	Position            float64 `json:"position"`             // mm from the interior surface
+	EquivalentThickness float64 `json:"equivalent_thickness"` // cumulative sd in m
+	Temperature         float64 `json:"temperature"`          // °C
+	SaturationPressure  float64 `json:"saturation_pressure"`  // Pa
+	VapourPressure      float64 `json:"vapour_pressure"`      // Pa
+	Condensation        bool    `json:"condensation"`
+}
+
+// CondensationMonth is the moisture balance of one calendar month
+type CondensationMonth struct {
+	Month              int     `json:"month"`               // 1 = January
+	OutdoorTemperature float64 `json:"outdoor_temperature"` // °C
+	OutdoorHumidity    float64 `json:"outdoor_humidity"`    // 0..1
+	Rate               float64 `json:"rate"`                // kg/m², negative when drying out
+	Accumulation       float64 `json:"accumulation"`        // kg/m² at the end of the month
+}
+
+// CondensationAnalysis is the result of a Glaser method check (ISO 13788)
+type CondensationAnalysis struct {
+	Conditions      DesignConditions    `json:"conditions"`
+	Location        string              `json:"location"`
+	Profile         []VapourPoint       `json:"profile"` // at the design conditions
+	Months          []CondensationMonth `json:"months"`
+	MaxAccumulation float64             `json:"max_accumulation"` // kg/m²
+	DriesOut        bool                `json:"dries_out"`
+}
+
+// HasCondensation reports whether any plane condenses at the design
+// conditions or moisture accumulates in any month
+func (a CondensationAnalysis) HasCondensation() bool {
+	for _, point := range a.Profile {
+		if point.Condensation {
+			return true
+		}
+	}
+
+	return a.MaxAccumulation > 0
+}
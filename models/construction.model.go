@@ -0,0 +1,29 @@
+package models
+
+// Construction is an existing build-up, e.g. plaster + brick + render, with
+// its layers ordered from the interior to the exterior
+type Construction struct {
+	Layers   []InsulationLayer `json:"layers"`
+	HeatFlow HeatFlowDirection `json:"heat_flow"`
+	Ground   *GroundFloor      `json:"ground,omitempty"` // set for slab-on-ground floors
+
+	Fixings      *Fixings          `json:"fixings,omitempty"`
+	InvertedRoof *InvertedRoof     `json:"inverted_roof,omitempty"`
+	Lambda       *LambdaConditions `json:"lambda,omitempty"` // nil uses the declared lambdas
+}
+
+// Result returns an InsulationResult with the construction as its base and
+// no insulation added yet
+func (c Construction) Result() InsulationResult {
+	return InsulationResult{
+		BaseLayers: c.Layers,
+		Layers:     []InsulationLayer{},
+		HeatFlow:   c.HeatFlow,
+		Ground:     c.Ground,
+
+		Fixings:      c.Fixings,
+		InvertedRoof: c.InvertedRoof,
+
+		LambdaConditions: c.Lambda,
+	}
+}
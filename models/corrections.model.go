@@ -0,0 +1,69 @@
+package models
+
+import "math"
+
+// Fixings are mechanical fasteners, e.g. the anchors of an ETICS facade,
+// running through the insulation, ISO 6946 Annex F.2
+type Fixings struct {
+	Count        float64 `json:"count"`        // per m²
+	Diameter     float64 `json:"diameter"`     // mm
+	Conductivity float64 `json:"conductivity"` // W/mK
+	Penetration  float64 `json:"penetration"`  // length inside the insulation, mm
+}
+
+// CrossSection returns the area of one fixing in m²
+func (f Fixings) CrossSection() float64 {
+	radius := f.Diameter / 2 / 1000
+
+	return math.Pi * radius * radius
+}
+
+// RoofDrainage is the kind of insulation and covering of an inverted roof,
+// which sets how much rainwater runs past the insulation to the membrane
+type RoofDrainage string
+
+const (
+	DrainageOpen   RoofDrainage = "open"   // butt joints, open covering such as gravel
+	DrainageClosed RoofDrainage = "closed" // rebated joints or closed covering such as paving
+)
+
+// RoofDrainages lists the drainage kinds in the order they are shown
+var RoofDrainages = []RoofDrainage{DrainageOpen, DrainageClosed}
+
+// Factor returns f·x in W·day/(m²·K·mm), the drainage factor times the
+// increase of heat loss by the rainwater, ISO 6946 Annex F.3
+func (d RoofDrainage) Factor() float64 {
+	if d == DrainageClosed {
+		return 0.03
+	}
+
+	return 0.04
+}
+
+// Label returns the name of the drainage kind shown to users
+func (d RoofDrainage) Label() string {
+	if d == DrainageClosed {
+		return "Rebated joints or closed covering (paving)"
+	}
+
+	return "Butt joints, open covering (gravel)"
+}
+
+// InvertedRoof is a roof with the insulation above the waterproofing
+type InvertedRoof struct {
+	Precipitation float64      `json:"precipitation"` // mean rainfall during the heating season, mm/day
+	Drainage      RoofDrainage `json:"drainage"`
+}
+
+// UCorrections are the ISO 6946 Annex F corrections added to the U-value,
+// in W/m²K
+type UCorrections struct {
+	Fixings       float64 `json:"fixings"`       // ΔUf
+	Precipitation float64 `json:"precipitation"` // ΔUr
+	Alpha         float64 `json:"alpha"`         // fixing coefficient α, 0.8 or less for recessed fixings
+}
+
+// Total returns the sum of the corrections
+func (c UCorrections) Total() float64 {
+	return c.Fixings + c.Precipitation
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -59,20 +60,27 @@ func MakeMigrations() {
 		log.Fatal(err)
 	}
 
-	// Recreate the table if it already exists
-	stmt = `DROP TABLE IF EXISTS materials;`
-	_, err = db.Exec(stmt)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	stmt = `CREATE TABLE IF NOT EXISTS materials (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		created_by INTEGER NOT NULL,
 		name VARCHAR(64) NOT NULL,
 		lambda REAL NOT NULL,
 		price REAL NOT NULL,
+		price_basis VARCHAR(8) NOT NULL DEFAULT 'm3',
+		price_thickness REAL NOT NULL DEFAULT 0,
+		package_coverage REAL NOT NULL DEFAULT 0,
+		currency VARCHAR(3) NOT NULL DEFAULT 'USD',
 		thickness REAL NOT NULL,
+		mu REAL NOT NULL DEFAULT 1,
+		density REAL NOT NULL DEFAULT 0,
+		specific_heat REAL NOT NULL DEFAULT 0,
+		gwp REAL NOT NULL DEFAULT 0,
+		gwp_basis VARCHAR(8) NOT NULL DEFAULT 'kg',
+		temperature_coefficient REAL NOT NULL DEFAULT 0,
+		moisture_coefficient REAL NOT NULL DEFAULT 0,
+		moisture_content REAL NOT NULL DEFAULT 0,
+		ageing_factor REAL NOT NULL DEFAULT 0,
+		fire_class VARCHAR(2) NOT NULL DEFAULT '',
 		description VARCHAR(255) NULL,
 		type VARCHAR(64) NOT NULL,
 		FOREIGN KEY(created_by) REFERENCES users(id)
@@ -83,22 +91,262 @@ func MakeMigrations() {
 		log.Fatal(err)
 	}
 
-	// Add items from materials.toml file
-	materials, err := ReadMaterialsFromTomlFile("./assets/data/materials.toml")
+	// Databases of older releases lack the columns added since
+	if err := addMissingColumns("materials", materialColumns); err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS material_thicknesses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		material_id INTEGER NOT NULL,
+		thickness REAL NOT NULL,
+		FOREIGN KEY(material_id) REFERENCES materials(id)
+	);`
+
+	_, err = db.Exec(stmt)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	stmt = `INSERT INTO materials (created_by, name, lambda, price, thickness, description, type)
-		VALUES(?, ?, ?, ?, ?, ?, ?);`
+	// Refresh the seed materials from materials.toml. They are matched by
+	// name, so the ids the building elements refer to and the materials of
+	// the users survive a restart.
+	materials, err := ReadMaterialsFromTomlFile("./assets/data/materials.toml")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for _, material := range materials {
-		_, err = db.Exec(stmt, material.CreatedBy, material.Name, material.Lambda, material.Price, material.Thickness, material.Description, material.Type)
-		if err != nil {
+		if err := seedMaterial(material); err != nil {
 			log.Fatal(err)
 		}
 	}
 
+	stmt = `CREATE TABLE IF NOT EXISTS buildings (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_by INTEGER NOT NULL,
+		name VARCHAR(64) NOT NULL,
+		description VARCHAR(255) NULL,
+		indoor_temperature REAL NOT NULL DEFAULT 20,
+		outdoor_temperature REAL NOT NULL DEFAULT -20,
+		thermal_bridge_allowance REAL NOT NULL DEFAULT 0.05,
+		FOREIGN KEY(created_by) REFERENCES users(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS building_energy (
+		building_id INTEGER PRIMARY KEY,
+		climate_location VARCHAR(64) NOT NULL,
+		floor_area REAL NOT NULL,
+		volume REAL NOT NULL,
+		air_change_rate REAL NOT NULL,
+		heat_recovery REAL NOT NULL DEFAULT 0,
+		internal_gains REAL NOT NULL,
+		heat_capacity REAL NOT NULL,
+		FOREIGN KEY(building_id) REFERENCES buildings(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS building_elements (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		building_id INTEGER NOT NULL,
+		name VARCHAR(64) NOT NULL,
+		type VARCHAR(16) NOT NULL,
+		orientation VARCHAR(2) NOT NULL DEFAULT '',
+		area REAL NOT NULL,
+		u_value REAL NOT NULL DEFAULT 0,
+		FOREIGN KEY(building_id) REFERENCES buildings(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS building_element_layers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		element_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		material_id INTEGER NOT NULL,
+		thickness REAL NOT NULL,
+		FOREIGN KEY(element_id) REFERENCES building_elements(id),
+		FOREIGN KEY(material_id) REFERENCES materials(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS building_element_layer_bridges (
+		element_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		material_id INTEGER NOT NULL,
+		fraction REAL NOT NULL,
+		PRIMARY KEY(element_id, position),
+		FOREIGN KEY(element_id) REFERENCES building_elements(id),
+		FOREIGN KEY(material_id) REFERENCES materials(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS building_element_air_layers (
+		element_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		heat_flow VARCHAR(16) NOT NULL DEFAULT '',
+		ventilation VARCHAR(16) NOT NULL,
+		PRIMARY KEY(element_id, position),
+		FOREIGN KEY(element_id) REFERENCES building_elements(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS element_fixings (
+		element_id INTEGER PRIMARY KEY,
+		count REAL NOT NULL,
+		diameter REAL NOT NULL,
+		conductivity REAL NOT NULL,
+		penetration REAL NOT NULL,
+		FOREIGN KEY(element_id) REFERENCES building_elements(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS inverted_roofs (
+		element_id INTEGER PRIMARY KEY,
+		precipitation REAL NOT NULL,
+		drainage VARCHAR(16) NOT NULL,
+		FOREIGN KEY(element_id) REFERENCES building_elements(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS ground_floors (
+		element_id INTEGER PRIMARY KEY,
+		perimeter REAL NOT NULL,
+		wall_thickness REAL NOT NULL,
+		soil VARCHAR(16) NOT NULL,
+		edge_insulation VARCHAR(16) NOT NULL DEFAULT '',
+		edge_insulation_width REAL NOT NULL DEFAULT 0,
+		edge_insulation_thickness REAL NOT NULL DEFAULT 0,
+		edge_insulation_lambda REAL NOT NULL DEFAULT 0,
+		FOREIGN KEY(element_id) REFERENCES building_elements(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS windows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_by INTEGER NOT NULL,
+		name VARCHAR(64) NOT NULL,
+		description VARCHAR(255) NULL,
+		kind VARCHAR(16) NOT NULL DEFAULT 'window',
+		width REAL NOT NULL,
+		height REAL NOT NULL,
+		frame_width REAL NOT NULL,
+		ug REAL NOT NULL,
+		uf REAL NOT NULL,
+		psi_g REAL NOT NULL,
+		g_value REAL NOT NULL DEFAULT 0,
+		FOREIGN KEY(created_by) REFERENCES users(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS element_windows (
+		element_id INTEGER PRIMARY KEY,
+		window_id INTEGER NOT NULL,
+		count INTEGER NOT NULL DEFAULT 1,
+		FOREIGN KEY(element_id) REFERENCES building_elements(id),
+		FOREIGN KEY(window_id) REFERENCES windows(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS junctions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_by INTEGER NOT NULL,
+		code VARCHAR(8) NOT NULL DEFAULT '',
+		name VARCHAR(64) NOT NULL,
+		description VARCHAR(255) NOT NULL DEFAULT '',
+		type VARCHAR(32) NOT NULL,
+		psi REAL NOT NULL,
+		dimensions VARCHAR(16) NOT NULL DEFAULT 'external',
+		FOREIGN KEY(created_by) REFERENCES users(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Refresh the catalogue from junctions.toml. Its entries are matched by
+	// code, so the elements using them and the junctions of the users stay.
+	junctions, err := LoadJunctionsFromTOML("./assets/data/junctions.toml")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, junction := range junctions {
+		if err := seedJunction(junction); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS element_junctions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		element_id INTEGER NOT NULL,
+		junction_id INTEGER NOT NULL,
+		length REAL NOT NULL,
+		FOREIGN KEY(element_id) REFERENCES building_elements(id),
+		FOREIGN KEY(junction_id) REFERENCES junctions(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stmt = `CREATE TABLE IF NOT EXISTS user_preferences (
+		user_id INTEGER PRIMARY KEY,
+		unit_system VARCHAR(16) NOT NULL DEFAULT 'metric',
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	);`
+
+	_, err = db.Exec(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	stmt = `CREATE TABLE IF NOT EXISTS todos (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		created_by INTEGER NOT NULL,
@@ -114,8 +362,139 @@ func MakeMigrations() {
 	}
 }
 
+// materialColumns are the columns materials gained after its first release
+var materialColumns = []string{
+	"price_basis VARCHAR(8) NOT NULL DEFAULT 'm3'",
+	"price_thickness REAL NOT NULL DEFAULT 0",
+	"package_coverage REAL NOT NULL DEFAULT 0",
+	"currency VARCHAR(3) NOT NULL DEFAULT 'USD'",
+	"mu REAL NOT NULL DEFAULT 1",
+	"density REAL NOT NULL DEFAULT 0",
+	"specific_heat REAL NOT NULL DEFAULT 0",
+	"gwp REAL NOT NULL DEFAULT 0",
+	"gwp_basis VARCHAR(8) NOT NULL DEFAULT 'kg'",
+	"temperature_coefficient REAL NOT NULL DEFAULT 0",
+	"moisture_coefficient REAL NOT NULL DEFAULT 0",
+	"moisture_content REAL NOT NULL DEFAULT 0",
+	"ageing_factor REAL NOT NULL DEFAULT 0",
+	"fire_class VARCHAR(2) NOT NULL DEFAULT ''",
+}
+
+// addMissingColumns adds the columns a table of an older database lacks.
+// SQLite has no ADD COLUMN IF NOT EXISTS, so the columns present are read
+// first.
+func addMissingColumns(table string, columns []string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return fmt.Errorf("error reading the columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	present := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("error reading the columns of %s: %w", table, err)
+		}
+		present[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading the columns of %s: %w", table, err)
+	}
+	rows.Close()
+
+	for _, column := range columns {
+		name := strings.Fields(column)[0]
+		if present[name] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column); err != nil {
+			return fmt.Errorf("error adding column %s to %s: %w", name, table, err)
+		}
+	}
+
+	return nil
+}
+
+// seedMaterial updates the seed material of the same name or adds it when
+// there is none yet, then replaces its board catalogue
+func seedMaterial(material Material) error {
+	var id uint64
+	err := db.QueryRow(`SELECT id FROM materials WHERE created_by = ? AND name = ? ORDER BY id LIMIT 1`,
+		material.CreatedBy, material.Name).Scan(&id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error looking up seed material %q: %w", material.Name, err)
+	}
+
+	values := []interface{}{material.Lambda, material.Price,
+		priceBasis(material.PriceBasis), material.PriceThickness, material.PackageCoverage, currency(material.Currency),
+		material.Thickness, material.Mu, material.Density, material.SpecificHeat, material.GWP, gwpBasis(material.GWPBasis),
+		material.TemperatureCoefficient, material.MoistureCoefficient, material.MoistureContent, material.AgeingFactor,
+		material.FireClass, material.Description, material.Type}
+
+	if id == 0 {
+		result, err := db.Exec(`INSERT INTO materials (lambda, price, price_basis, price_thickness, package_coverage, currency,
+			thickness, mu, density, specific_heat, gwp, gwp_basis,
+			temperature_coefficient, moisture_coefficient, moisture_content, ageing_factor, fire_class, description, type,
+			created_by, name)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			append(values, material.CreatedBy, material.Name)...)
+		if err != nil {
+			return fmt.Errorf("error adding seed material %q: %w", material.Name, err)
+		}
+
+		inserted, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("error adding seed material %q: %w", material.Name, err)
+		}
+		id = uint64(inserted)
+	} else {
+		_, err := db.Exec(`UPDATE materials SET lambda = ?, price = ?, price_basis = ?, price_thickness = ?, package_coverage = ?, currency = ?,
+			thickness = ?, mu = ?, density = ?, specific_heat = ?, gwp = ?, gwp_basis = ?,
+			temperature_coefficient = ?, moisture_coefficient = ?, moisture_content = ?, ageing_factor = ?, fire_class = ?, description = ?, type = ?
+			WHERE id = ?`,
+			append(values, id)...)
+		if err != nil {
+			return fmt.Errorf("error updating seed material %q: %w", material.Name, err)
+		}
+	}
+
+	return SetMaterialThicknesses(id, material.Thicknesses)
+}
+
+// seedJunction updates the catalogue junction with the same code or adds it
+// when there is none yet
+func seedJunction(junction Junction) error {
+	result, err := db.Exec(`UPDATE junctions SET name = ?, description = ?, type = ?, psi = ?, dimensions = ?
+		WHERE created_by = ? AND code = ?`,
+		junction.Name, junction.Description, junction.Type, junction.Psi, dimensions(junction.Dimensions),
+		junction.CreatedBy, junction.Code)
+	if err != nil {
+		return fmt.Errorf("error updating catalogue junction %s: %w", junction.Code, err)
+	}
+	if i, err := result.RowsAffected(); err == nil && i > 0 {
+		return nil
+	}
+
+	_, err = db.Exec(`INSERT INTO junctions (created_by, code, name, description, type, psi, dimensions)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		junction.CreatedBy, junction.Code, junction.Name, junction.Description, junction.Type, junction.Psi,
+		dimensions(junction.Dimensions))
+	if err != nil {
+		return fmt.Errorf("error adding catalogue junction %s: %w", junction.Code, err)
+	}
+
+	return nil
+}
+
 func GetMaterialsByIDs(ids []string) ([]Material, error) {
-	query := `SELECT id, created_by, name, description, lambda, price, thickness, type FROM materials WHERE id IN (?` + strings.Repeat(",?", len(ids)-1) + `)`
+	if len(ids) == 0 {
+		return []Material{}, nil
+	}
+
+	query := `SELECT id, created_by, name, description, lambda, price, price_basis, price_thickness, package_coverage, currency,
+		thickness, mu, density, specific_heat, gwp, gwp_basis,
+		temperature_coefficient, moisture_coefficient, moisture_content, ageing_factor, fire_class, type FROM materials WHERE id IN (?` + strings.Repeat(",?", len(ids)-1) + `)`
 
 	// Convert []string to []interface{}
 	args := make([]interface{}, len(ids))
@@ -132,35 +511,129 @@ func GetMaterialsByIDs(ids []string) ([]Material, error) {
 	var materials []Material
 	for rows.Next() {
 		var m Material
-		err := rows.Scan(&m.ID, &m.CreatedBy, &m.Name, &m.Description, &m.Lambda, &m.Price, &m.Thickness, &m.Type)
+		err := rows.Scan(&m.ID, &m.CreatedBy, &m.Name, &m.Description, &m.Lambda, &m.Price,
+			&m.PriceBasis, &m.PriceThickness, &m.PackageCoverage, &m.Currency,
+			&m.Thickness, &m.Mu, &m.Density, &m.SpecificHeat, &m.GWP, &m.GWPBasis,
+			&m.TemperatureCoefficient, &m.MoistureCoefficient, &m.MoistureContent, &m.AgeingFactor, &m.FireClass, &m.Type)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning material row: %w", err)
 		}
 		materials = append(materials, m)
 	}
 
-	return materials, nil
+	return materials, attachThicknesses(materials)
 }
 
 func AddMaterial(material Material) error {
 
-	stmt := `INSERT INTO materials (created_by, name, lambda, price, thickness, description, type)
-		VALUES(?, ?, ?, ?, ?, ?, ?);`
+	stmt := `INSERT INTO materials (created_by, name, lambda, price, price_basis, price_thickness, package_coverage, currency,
+		thickness, mu, density, specific_heat, gwp, gwp_basis,
+		temperature_coefficient, moisture_coefficient, moisture_content, ageing_factor, fire_class, description, type)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 
-	log.Println(stmt, material.CreatedBy, material.Name, material.Lambda, material.Price, material.Thickness, material.Description, material.Type)
+	log.Println(stmt, material.CreatedBy, material.Name, material.Lambda, material.Price,
+		priceBasis(material.PriceBasis), material.PriceThickness, material.PackageCoverage, currency(material.Currency),
+		material.Thickness, material.Mu, material.Density, material.SpecificHeat, material.GWP, gwpBasis(material.GWPBasis),
+		material.TemperatureCoefficient, material.MoistureCoefficient, material.MoistureContent, material.AgeingFactor, material.FireClass, material.Description, material.Type)
 
-	_, err := db.Exec(stmt, material.CreatedBy, material.Name, material.Lambda, material.Price, material.Thickness, material.Description, material.Type)
+	result, err := db.Exec(stmt, material.CreatedBy, material.Name, material.Lambda, material.Price,
+		priceBasis(material.PriceBasis), material.PriceThickness, material.PackageCoverage, currency(material.Currency),
+		material.Thickness, material.Mu, material.Density, material.SpecificHeat, material.GWP, gwpBasis(material.GWPBasis),
+		material.TemperatureCoefficient, material.MoistureCoefficient, material.MoistureContent, material.AgeingFactor, material.FireClass, material.Description, material.Type)
 
 	if err != nil {
 		return fmt.Errorf("error adding material: %w", err)
 	}
 
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error adding material: %w", err)
+	}
+
+	return SetMaterialThicknesses(uint64(id), material.Thicknesses)
+}
+
+// gwpBasis defaults an unset basis to per kg
+func gwpBasis(basis GWPBasis) GWPBasis {
+	if basis == "" {
+		return GWPPerKilogram
+	}
+
+	return basis
+}
+
+func priceBasis(basis PriceBasis) PriceBasis {
+	if basis == "" {
+		return PricePerCubicMetre
+	}
+
+	return basis
+}
+
+func currency(code string) string {
+	if code == "" {
+		return DefaultCurrency
+	}
+
+	return code
+}
+
+// GetMaterialThicknesses returns the available board thicknesses of a
+// material in mm, thinnest first
+func GetMaterialThicknesses(materialID uint64) ([]float64, error) {
+	rows, err := db.Query(`SELECT thickness FROM material_thicknesses WHERE material_id = ? ORDER BY thickness`, materialID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying material thicknesses: %w", err)
+	}
+	defer rows.Close()
+
+	thicknesses := []float64{}
+	for rows.Next() {
+		var thickness float64
+		if err := rows.Scan(&thickness); err != nil {
+			return nil, fmt.Errorf("error scanning material thickness row: %w", err)
+		}
+		thicknesses = append(thicknesses, thickness)
+	}
+
+	return thicknesses, nil
+}
+
+// SetMaterialThicknesses replaces the board catalogue of a material
+func SetMaterialThicknesses(materialID uint64, thicknesses []float64) error {
+	_, err := db.Exec(`DELETE FROM material_thicknesses WHERE material_id = ?`, materialID)
+	if err != nil {
+		return fmt.Errorf("error clearing material thicknesses: %w", err)
+	}
+
+	for _, thickness := range thicknesses {
+		_, err = db.Exec(`INSERT INTO material_thicknesses (material_id, thickness) VALUES(?, ?)`, materialID, thickness)
+		if err != nil {
+			return fmt.Errorf("error adding material thickness: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// attachThicknesses loads the board catalogue of every material
+func attachThicknesses(materials []Material) error {
+	for i := range materials {
+		thicknesses, err := GetMaterialThicknesses(materials[i].ID)
+		if err != nil {
+			return err
+		}
+		materials[i].Thicknesses = thicknesses
+	}
+
 	return nil
 }
 
 func GetAllMaterials() ([]Material, error) {
 
-	stmt := `SELECT id, created_by, name, description, lambda, price, thickness, type FROM materials;`
+	stmt := `SELECT id, created_by, name, description, lambda, price, price_basis, price_thickness, package_coverage, currency,
+		thickness, mu, density, specific_heat, gwp, gwp_basis,
+		temperature_coefficient, moisture_coefficient, moisture_content, ageing_factor, fire_class, type FROM materials;`
 	log.Println(stmt)
 	rows, err := db.Query(stmt)
 	if err != nil {
@@ -172,14 +645,17 @@ func GetAllMaterials() ([]Material, error) {
 
 	for rows.Next() {
 		var m Material
-		err := rows.Scan(&m.ID, &m.CreatedBy, &m.Name, &m.Description, &m.Lambda, &m.Price, &m.Thickness, &m.Type)
+		err := rows.Scan(&m.ID, &m.CreatedBy, &m.Name, &m.Description, &m.Lambda, &m.Price,
+			&m.PriceBasis, &m.PriceThickness, &m.PackageCoverage, &m.Currency,
+			&m.Thickness, &m.Mu, &m.Density, &m.SpecificHeat, &m.GWP, &m.GWPBasis,
+			&m.TemperatureCoefficient, &m.MoistureCoefficient, &m.MoistureContent, &m.AgeingFactor, &m.FireClass, &m.Type)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning material row: %w", err)
 		}
 		materials = append(materials, m)
 	}
 
-	return materials, nil
+	return materials, attachThicknesses(materials)
 }
 
 /*
@@ -0,0 +1,15 @@
+package models
+
+// DynamicPeriod is the period of the ISO 13786 daily cycle in hours
+const DynamicPeriod = 24.0
+
+// DynamicProperties are the ISO 13786 periodic thermal properties of a
+// build-up for a daily temperature cycle on the exterior side
+type DynamicProperties struct {
+	PeriodicTransmittance float64  `json:"periodic_transmittance"` // |Y12|, W/m²K
+	DecrementFactor       float64  `json:"decrement_factor"`       // |Y12| / U
+	TimeShift             float64  `json:"time_shift"`             // h the indoor heat flow peaks after the outdoor temperature
+	InternalHeatCapacity  float64  `json:"internal_heat_capacity"` // κ1, kJ/m²K
+	ExternalHeatCapacity  float64  `json:"external_heat_capacity"` // κ2, kJ/m²K
+	Massless              []string `json:"massless,omitempty"`     // layers without a density or specific heat, counted as resistances only
+}
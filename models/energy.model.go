@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// EnergyInputs describe a building for the monthly heating demand beyond
+// its envelope: the climate, the heated floor area and volume, ventilation,
+// internal gains and the internal heat capacity
+type EnergyInputs struct {
+	ClimateLocation string  `json:"climate_location"`
+	FloorArea       float64 `json:"floor_area"`      // heated floor area, m²
+	Volume          float64 `json:"volume"`          // heated air volume, m³
+	AirChangeRate   float64 `json:"air_change_rate"` // ventilation and infiltration, 1/h
+	HeatRecovery    float64 `json:"heat_recovery"`   // efficiency of the ventilation heat recovery, 0..1
+	InternalGains   float64 `json:"internal_gains"`  // W per m² of floor area
+	HeatCapacity    float64 `json:"heat_capacity"`   // internal heat capacity, kJ/K per m² of floor area
+}
+
+// DefaultEnergyInputs are a typical dwelling of medium mass, ISO 13790
+// Table 12, with natural ventilation
+var DefaultEnergyInputs = EnergyInputs{
+	AirChangeRate: 0.5,
+	InternalGains: 3.0,
+	HeatCapacity:  165,
+}
+
+// MonthlyEnergy is the heat balance of one month, in kWh
+type MonthlyEnergy struct {
+	Month              int     `json:"month"`               // 1 for January
+	OutdoorTemperature float64 `json:"outdoor_temperature"` // °C
+	Transmission       float64 `json:"transmission"`
+	Ventilation        float64 `json:"ventilation"`
+	InternalGains      float64 `json:"internal_gains"`
+	SolarGains         float64 `json:"solar_gains"`
+	Utilisation        float64 `json:"utilisation"` // gain utilisation factor η
+	Demand             float64 `json:"demand"`
+}
+
+// Losses are the transmission and ventilation losses of the month
+func (m MonthlyEnergy) Losses() float64 {
+	return m.Transmission + m.Ventilation
+}
+
+// Gains are the internal and solar gains of the month
+func (m MonthlyEnergy) Gains() float64 {
+	return m.InternalGains + m.SolarGains
+}
+
+// EnergyDemand is the monthly quasi-steady-state heating demand of a
+// building
+type EnergyDemand struct {
+	Inputs       EnergyInputs    `json:"inputs"`
+	HT           float64         `json:"h_t"`           // transmission, W/K
+	HV           float64         `json:"h_v"`           // ventilation, W/K
+	TimeConstant float64         `json:"time_constant"` // τ, h
+	Months       []MonthlyEnergy `json:"months"`
+	Annual       float64         `json:"annual"`         // kWh/year
+	PerFloorArea float64         `json:"per_floor_area"` // kWh/m²·year
+}
+
+// getBuildingEnergy loads the energy inputs of a building, nil when it has
+// none
+func getBuildingEnergy(buildingID uint64) (*EnergyInputs, error) {
+	query := `SELECT climate_location, floor_area, volume, air_change_rate, heat_recovery, internal_gains, heat_capacity
+		FROM building_energy WHERE building_id = ?`
+
+	inputs := EnergyInputs{}
+	err := db.QueryRow(query, buildingID).Scan(
+		&inputs.ClimateLocation,
+		&inputs.FloorArea,
+		&inputs.Volume,
+		&inputs.AirChangeRate,
+		&inputs.HeatRecovery,
+		&inputs.InternalGains,
+		&inputs.HeatCapacity,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading energy inputs of building #%d: %w", buildingID, err)
+	}
+
+	return &inputs, nil
+}
+
+// setBuildingEnergy replaces the energy inputs of a building, nil removes
+// them
+func setBuildingEnergy(buildingID uint64, inputs *EnergyInputs) error {
+	_, err := db.Exec(`DELETE FROM building_energy WHERE building_id = ?`, buildingID)
+	if err != nil {
+		return fmt.Errorf("error clearing energy inputs: %w", err)
+	}
+	if inputs == nil {
+		return nil
+	}
+
+	_, err = db.Exec(`INSERT INTO building_energy (building_id, climate_location, floor_area, volume, air_change_rate, heat_recovery, internal_gains, heat_capacity)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		buildingID, inputs.ClimateLocation, inputs.FloorArea, inputs.Volume, inputs.AirChangeRate,
+		inputs.HeatRecovery, inputs.InternalGains, inputs.HeatCapacity)
+	if err != nil {
+		return fmt.Errorf("error adding energy inputs: %w", err)
+	}
+
+	return nil
+}
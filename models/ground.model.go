@@ -0,0 +1,79 @@
+package models
+
+// SoilType selects the thermal conductivity of the ground, ISO 13370 Table 1
+type SoilType string
+
+const (
+	SoilClay SoilType = "clay"
+	SoilSand SoilType = "sand"
+	SoilRock SoilType = "rock"
+)
+
+// SoilTypes lists the soil types in the order they are shown
+var SoilTypes = []SoilType{SoilClay, SoilSand, SoilRock}
+
+// Conductivity returns λ of the ground in W/mK. Unknown soil is treated as
+// clay or silt, the default of ISO 13370.
+func (s SoilType) Conductivity() float64 {
+	switch s {
+	case SoilSand:
+		return 2.0
+	case SoilRock:
+		return 3.5
+	default:
+		return 1.5
+	}
+}
+
+// Label names the soil type for forms
+func (s SoilType) Label() string {
+	switch s {
+	case SoilSand:
+		return "Sand or gravel (λ = 2.0)"
+	case SoilRock:
+		return "Homogeneous rock (λ = 3.5)"
+	default:
+		return "Clay or silt (λ = 1.5)"
+	}
+}
+
+// EdgeInsulation is the position of the insulation strip along the
+// perimeter of a slab, if there is one
+type EdgeInsulation string
+
+const (
+	EdgeInsulationNone       EdgeInsulation = ""
+	EdgeInsulationHorizontal EdgeInsulation = "horizontal"
+	EdgeInsulationVertical   EdgeInsulation = "vertical"
+)
+
+// GroundFloor describes a slab-on-ground floor for ISO 13370. The floor
+// build-up itself comes from the layers of the construction.
+type GroundFloor struct {
+	Area                    float64        `json:"area"`           // m²
+	Perimeter               float64        `json:"perimeter"`      // exposed perimeter, m
+	WallThickness           float64        `json:"wall_thickness"` // mm
+	Soil                    SoilType       `json:"soil"`
+	EdgeInsulation          EdgeInsulation `json:"edge_insulation,omitempty"`
+	EdgeInsulationWidth     float64        `json:"edge_insulation_width,omitempty"`     // width, or depth when vertical, m
+	EdgeInsulationThickness float64        `json:"edge_insulation_thickness,omitempty"` // mm
+	EdgeInsulationLambda    float64        `json:"edge_insulation_lambda,omitempty"`    // W/mK
+}
+
+// CharacteristicDimension returns B' = A / (½ P) in m
+func (g GroundFloor) CharacteristicDimension() float64 {
+	if g.Perimeter <= 0 {
+		return 0
+	}
+
+	return g.Area / (0.5 * g.Perimeter)
+}
+
+// GroundFloorResult holds the steps of the ISO 13370 calculation
+type GroundFloorResult struct {
+	CharacteristicDimension float64 `json:"characteristic_dimension"` // B', m
+	EquivalentThickness     float64 `json:"equivalent_thickness"`     // dt, m
+	BasicUValue             float64 `json:"basic_u_value"`            // U0 without edge insulation, W/m²K
+	EdgePsi                 float64 `json:"edge_psi"`                 // ΔΨ of the edge insulation, W/mK
+	UValue                  float64 `json:"u_value"`                  // W/m²K
+}
@@ -0,0 +1,265 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// JunctionType groups junctions like the catalogue of ISO 14683
+type JunctionType string
+
+const (
+	JunctionRoof              JunctionType = "roof"
+	JunctionBalcony           JunctionType = "balcony"
+	JunctionCorner            JunctionType = "corner"
+	JunctionIntermediateFloor JunctionType = "intermediate-floor"
+	JunctionInternalWall      JunctionType = "internal-wall"
+	JunctionGroundFloor       JunctionType = "ground-floor"
+	JunctionPillar            JunctionType = "pillar"
+	JunctionWindow            JunctionType = "window"
+	JunctionDoor              JunctionType = "door"
+)
+
+// JunctionTypes lists the junction types in the order they are shown
+var JunctionTypes = []JunctionType{
+	JunctionRoof, JunctionBalcony, JunctionCorner, JunctionIntermediateFloor, JunctionInternalWall,
+	JunctionGroundFloor, JunctionPillar, JunctionWindow, JunctionDoor,
+}
+
+// DimensionSystem is how the areas of the flanking elements are measured,
+// which the ψ-value of a junction depends on
+type DimensionSystem string
+
+const (
+	DimensionsExternal        DimensionSystem = "external"
+	DimensionsOverallInternal DimensionSystem = "overall-internal"
+	DimensionsInternal        DimensionSystem = "internal"
+)
+
+// DimensionSystems lists the dimension systems of ISO 14683
+var DimensionSystems = []DimensionSystem{DimensionsExternal, DimensionsOverallInternal, DimensionsInternal}
+
+// Junction is a linear thermal bridge of the user's library or of the
+// bundled catalogue
+type Junction struct {
+	ID          uint64          `json:"id" toml:"id"`
+	CreatedBy   uint64          `json:"created_by" toml:"created_by"`
+	Code        string          `json:"code,omitempty" toml:"code"` // ISO 14683 designation like B1
+	Name        string          `json:"name" toml:"name"`
+	Description string          `json:"description,omitempty" toml:"description"`
+	Type        JunctionType    `json:"type" toml:"type"`
+	Psi         float64         `json:"psi" toml:"psi"` // W/mK
+	Dimensions  DimensionSystem `json:"dimensions" toml:"dimensions"`
+}
+
+// PlacedJunction is a junction along an element over a length
+type PlacedJunction struct {
+	Junction Junction `json:"junction"`
+	Length   float64  `json:"length"` // m
+}
+
+// HeatTransfer is ψ·l of the placed junction, W/K
+func (p PlacedJunction) HeatTransfer() float64 {
+	return p.Junction.Psi * p.Length
+}
+
+// ElementJunctions are the junctions along an element of the calculator,
+// spread over the area of the element
+type ElementJunctions struct {
+	Area         float64          `json:"area"` // m²
+	Junctions    []PlacedJunction `json:"junctions"`
+	HeatTransfer float64          `json:"heat_transfer"` // Σ ψ·l, W/K
+	UValue       float64          `json:"u_value"`       // U + Σ ψ·l / A, W/m²K
+}
+
+// Label names a junction with its catalogue code
+func (j Junction) Label() string {
+	if j.Code == "" {
+		return j.Name
+	}
+	return j.Code + " " + j.Name
+}
+
+// JunctionsTOMLData represents the structure of the junction catalogue
+type JunctionsTOMLData struct {
+	Junctions []Junction `toml:"junction"`
+}
+
+// LoadJunctionsFromTOML loads the bundled junction catalogue
+func LoadJunctionsFromTOML(filename string) ([]Junction, error) {
+	var data JunctionsTOMLData
+
+	if _, err := toml.DecodeFile(filename, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode junctions from TOML file: %w", err)
+	}
+
+	codes := map[string]bool{}
+	for _, junction := range data.Junctions {
+		if junction.Code == "" {
+			return nil, fmt.Errorf("junction %q needs a code", junction.Name)
+		}
+		if codes[junction.Code] {
+			return nil, fmt.Errorf("junction code %q is used twice", junction.Code)
+		}
+		codes[junction.Code] = true
+	}
+
+	return data.Junctions, nil
+}
+
+// dimensions falls back to external dimensions for junctions without a
+// dimension system
+func dimensions(system DimensionSystem) DimensionSystem {
+	if system == "" {
+		return DimensionsExternal
+	}
+	return system
+}
+
+// GetAllJunctions returns the junctions of the user and of the catalogue
+func (j *Junction) GetAllJunctions() ([]Junction, error) {
+	query := `SELECT id, created_by, code, name, description, type, psi, dimensions
+		FROM junctions WHERE created_by IN (?, 1337) ORDER BY type, code, name`
+
+	rows, err := db.Query(query, j.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("error querying junctions: %w", err)
+	}
+	defer rows.Close()
+
+	junctions := []Junction{}
+	for rows.Next() {
+		var p Junction
+		err := rows.Scan(&p.ID, &p.CreatedBy, &p.Code, &p.Name, &p.Description, &p.Type, &p.Psi, &p.Dimensions)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning junction row: %w", err)
+		}
+		junctions = append(junctions, p)
+	}
+
+	return junctions, nil
+}
+
+// GetJunctionById returns a junction of the user
+func (j *Junction) GetJunctionById() (Junction, error) {
+	query := `SELECT id, created_by, code, name, description, type, psi, dimensions
+		FROM junctions WHERE created_by = ? AND id = ?`
+
+	var p Junction
+	err := db.QueryRow(query, j.CreatedBy, j.ID).Scan(
+		&p.ID,
+		&p.CreatedBy,
+		&p.Code,
+		&p.Name,
+		&p.Description,
+		&p.Type,
+		&p.Psi,
+		&p.Dimensions,
+	)
+	if err != nil {
+		return Junction{}, err
+	}
+
+	return p, nil
+}
+
+func (j *Junction) CreateJunction() error {
+	query := `INSERT INTO junctions (created_by, code, name, description, type, psi, dimensions)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := db.Exec(query, j.CreatedBy, j.Code, j.Name, j.Description, j.Type, j.Psi, dimensions(j.Dimensions))
+	if err != nil {
+		return fmt.Errorf("error adding junction: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error adding junction: %w", err)
+	}
+	j.ID = uint64(id)
+
+	return nil
+}
+
+func (j *Junction) UpdateJunction() error {
+	query := `UPDATE junctions SET code = ?, name = ?, description = ?, type = ?, psi = ?, dimensions = ?
+		WHERE created_by = ? AND id = ?`
+
+	result, err := db.Exec(query, j.Code, j.Name, j.Description, j.Type, j.Psi, dimensions(j.Dimensions), j.CreatedBy, j.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	return nil
+}
+
+// DeleteJunction removes a junction of the user unless a building element
+// uses it
+func (j *Junction) DeleteJunction() error {
+	var uses int
+	err := db.QueryRow(`SELECT COUNT(*) FROM element_junctions WHERE junction_id = ?`, j.ID).Scan(&uses)
+	if err != nil {
+		return err
+	}
+	if uses > 0 {
+		return fmt.Errorf("the junction is used by %d building element(s)", uses)
+	}
+
+	result, err := db.Exec(`DELETE FROM junctions WHERE created_by = ? AND id = ?`, j.CreatedBy, j.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	return nil
+}
+
+// getElementJunctions loads the junctions placed along an element
+func getElementJunctions(elementID uint64) ([]PlacedJunction, error) {
+	rows, err := db.Query(`SELECT j.id, j.created_by, j.code, j.name, j.description, j.type, j.psi, j.dimensions, ej.length
+		FROM element_junctions ej JOIN junctions j ON j.id = ej.junction_id WHERE ej.element_id = ? ORDER BY ej.id`, elementID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying element junctions: %w", err)
+	}
+	defer rows.Close()
+
+	placed := []PlacedJunction{}
+	for rows.Next() {
+		var p PlacedJunction
+		j := &p.Junction
+		err := rows.Scan(&j.ID, &j.CreatedBy, &j.Code, &j.Name, &j.Description, &j.Type, &j.Psi, &j.Dimensions, &p.Length)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning element junction row: %w", err)
+		}
+		placed = append(placed, p)
+	}
+
+	return placed, nil
+}
+
+// setElementJunctions replaces the junctions placed along an element
+func setElementJunctions(elementID uint64, junctions []PlacedJunction) error {
+	_, err := db.Exec(`DELETE FROM element_junctions WHERE element_id = ?`, elementID)
+	if err != nil {
+		return fmt.Errorf("error clearing element junctions: %w", err)
+	}
+
+	for _, placed := range junctions {
+		_, err := db.Exec(`INSERT INTO element_junctions (element_id, junction_id, length) VALUES(?, ?, ?)`,
+			elementID, placed.Junction.ID, placed.Length)
+		if err != nil {
+			return fmt.Errorf("error adding element junction: %w", err)
+		}
+	}
+
+	return nil
+}
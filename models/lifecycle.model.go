@@ -0,0 +1,31 @@
+package models
+
+// EconomicInputs are the assumptions of the lifecycle cost analysis. Rates
+// are fractions (0.03 = 3 %).
+type EconomicInputs struct {
+	DegreeDays     float64 `json:"degree_days"`     // heating degree-days, Kd
+	Efficiency     float64 `json:"efficiency"`      // seasonal efficiency of the heating system, 0..1
+	EnergyPrice    float64 `json:"energy_price"`    // per kWh of delivered energy
+	Escalation     float64 `json:"escalation"`      // yearly rise of the energy price
+	DiscountRate   float64 `json:"discount_rate"`   // yearly
+	AnalysisPeriod int     `json:"analysis_period"` // years
+}
+
+// LifecycleAnalysis compares the running costs of an insulated build-up with
+// the construction before insulation, per m²
+type LifecycleAnalysis struct {
+	Inputs            EconomicInputs `json:"inputs"`
+	BaseUValue        float64        `json:"base_u_value"`        // W/m²K
+	AnnualEnergySaved float64        `json:"annual_energy_saved"` // kWh/m² of delivered energy
+	AnnualSavings     float64        `json:"annual_savings"`      // in the first year
+	SimplePayback     float64        `json:"simple_payback"`      // years, -1 when nothing is saved
+	DiscountedPayback float64        `json:"discounted_payback"`  // years, -1 when not within the analysis period
+	PresentSavings    float64        `json:"present_savings"`     // discounted savings over the analysis period
+	NPV               float64        `json:"npv"`                 // present savings less the investment
+}
+
+// PaysBack reports whether the discounted savings recover the investment
+// within the analysis period
+func (l LifecycleAnalysis) PaysBack() bool {
+	return l.DiscountedPayback >= 0
+}
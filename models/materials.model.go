@@ -6,30 +6,357 @@ import (
 	"io/ioutil"
 
 	"github.com/BurntSushi/toml"
+	"github.com/kaloszer/insulationCalcHtmx/units"
 )
 
 type Material struct {
-	ID          uint64  `json:"id" toml:"id"`
-	CreatedBy   uint64  `json:"created_by" toml:"created_by"`
-	Name        string  `json:"name" toml:"name"`
-	Description string  `json:"description,omitempty" toml:"description"`
-	Lambda      float64 `json:"lambda" toml:"lambda"`
-	Price       float64 `json:"price,omitempty" toml:"price"`
-	Thickness   float64 `json:"thickness" toml:"thickness"`
-	Type        string  `json:"type" toml:"type"`
+	ID           uint64    `json:"id" toml:"id"`
+	CreatedBy    uint64    `json:"created_by" toml:"created_by"`
+	Name         string    `json:"name" toml:"name"`
+	Description  string    `json:"description,omitempty" toml:"description"`
+	Lambda       float64   `json:"lambda" toml:"lambda"`
+	Price        float64   `json:"price,omitempty" toml:"price"` // in Currency per PriceBasis
+	Thickness    float64   `json:"thickness" toml:"thickness"`
+	Thicknesses  []float64 `json:"thicknesses,omitempty" toml:"thicknesses"` // available boards in mm
+	Mu           float64   `json:"mu" toml:"mu"`                             // water vapour diffusion resistance factor
+	Density      float64   `json:"density" toml:"density"`                   // kg/m³
+	SpecificHeat float64   `json:"specific_heat" toml:"specific_heat"`       // J/kgK
+	GWP          float64   `json:"gwp" toml:"gwp"`                           // A1–A3 global warming potential, kgCO2e per GWPBasis
+	GWPBasis     GWPBasis  `json:"gwp_basis" toml:"gwp_basis"`
+	Type         string    `json:"type" toml:"type"`
+	FireClass    FireClass `json:"fire_class,omitempty" toml:"fire_class"` // EN 13501-1 reaction to fire, empty when not classified
+
+	PriceBasis      PriceBasis `json:"price_basis" toml:"price_basis"`
+	PriceThickness  float64    `json:"price_thickness,omitempty" toml:"price_thickness"`   // mm the m² and package prices refer to
+	PackageCoverage float64    `json:"package_coverage,omitempty" toml:"package_coverage"` // m² covered by one package
+	Currency        string     `json:"currency" toml:"currency"`                           // ISO 4217 code
+
+	// ISO 10456 conversion of the declared lambda to design conditions
+	TemperatureCoefficient float64 `json:"temperature_coefficient,omitempty" toml:"temperature_coefficient"` // fT, 1/K
+	MoistureCoefficient    float64 `json:"moisture_coefficient,omitempty" toml:"moisture_coefficient"`       // fψ, per m³/m³
+	MoistureContent        float64 `json:"moisture_content,omitempty" toml:"moisture_content"`               // ψ in service, m³/m³
+	AgeingFactor           float64 `json:"ageing_factor,omitempty" toml:"ageing_factor"`                     // Fa, 0 for none
 }
 
+// LambdaConditions are the ISO 10456 design conditions declared lambdas are
+// converted to
+type LambdaConditions struct {
+	Temperature float64 `json:"temperature"` // mean temperature of the construction, °C
+}
+
+// Material types, the sections of materials.toml. The Annex F corrections
+// count the base layers of insulation materials as insulation.
+const (
+	MaterialInsulation = "insulation"
+	MaterialWall       = "wall"
+	MaterialOther      = "other"
+)
+
+// MaterialTypes lists the material types in the order they are shown
+var MaterialTypes = []string{MaterialInsulation, MaterialWall, MaterialOther}
+
+// GWPBasis is the declared unit a GWP figure refers to
+type GWPBasis string
+
+const (
+	GWPPerKilogram   GWPBasis = "kg"
+	GWPPerCubicMetre GWPBasis = "m3"
+)
+
+// FireClass is the EN 13501-1 Euroclass of the reaction to fire of a
+// material, without the smoke and droplet subclasses
+type FireClass string
+
+const (
+	FireClassA1 FireClass = "A1"
+	FireClassA2 FireClass = "A2"
+	FireClassB  FireClass = "B"
+	FireClassC  FireClass = "C"
+	FireClassD  FireClass = "D"
+	FireClassE  FireClass = "E"
+	FireClassF  FireClass = "F"
+)
+
+// FireClasses lists the Euroclasses from the best to the worst
+var FireClasses = []FireClass{FireClassA1, FireClassA2, FireClassB, FireClassC, FireClassD, FireClassE, FireClassF}
+
+// Valid reports whether the class is a Euroclass
+func (f FireClass) Valid() bool {
+	return f.rank() >= 0
+}
+
+// Meets reports whether the class is minimum or better. Any class meets an
+// empty minimum, and unclassified materials meet nothing else.
+func (f FireClass) Meets(minimum FireClass) bool {
+	if minimum == "" {
+		return true
+	}
+	return f.Valid() && f.rank() <= minimum.rank()
+}
+
+func (f FireClass) rank() int {
+	for i, class := range FireClasses {
+		if class == f {
+			return i
+		}
+	}
+	return -1
+}
+
+// PriceBasis is the unit a material price is quoted in
+type PriceBasis string
+
+const (
+	PricePerSquareMetre PriceBasis = "m2" // per m² at PriceThickness
+	PricePerCubicMetre  PriceBasis = "m3"
+	PricePerPackage     PriceBasis = "package" // per package of PackageCoverage m² at PriceThickness
+)
+
+// PriceBases lists the price bases in the order they are shown
+var PriceBases = []PriceBasis{PricePerSquareMetre, PricePerCubicMetre, PricePerPackage}
+
+// DefaultCurrency is the currency of materials that do not declare one
+const DefaultCurrency = "USD"
+
+// Quantity returns the quantity a price on the basis is per, false for
+// packages, which read the same in any unit system
+func (b PriceBasis) Quantity() (units.Quantity, bool) {
+	switch b {
+	case PricePerSquareMetre:
+		return units.PerArea, true
+	case PricePerPackage:
+		return 0, false
+	default:
+		return units.PerVolume, true
+	}
+}
+
+// PricePerCubicMetre normalises the price of the material to its currency
+// per m³, 0 when the basis lacks the thickness or coverage it needs
+func (m Material) PricePerCubicMetre() float64 {
+	switch m.PriceBasis {
+	case PricePerSquareMetre:
+		if m.PriceThickness <= 0 {
+			return 0
+		}
+		return m.Price / (m.PriceThickness / 1000)
+	case PricePerPackage:
+		if m.PriceThickness <= 0 || m.PackageCoverage <= 0 {
+			return 0
+		}
+		return m.Price / (m.PackageCoverage * m.PriceThickness / 1000)
+	default:
+		return m.Price
+	}
+}
+
+// CommonCurrency returns the currency all the materials are priced in, so
+// their costs can be added up. Mixed currencies are an error.
+func CommonCurrency(materials []Material) (string, error) {
+	currency := ""
+	for _, material := range materials {
+		code := material.Currency
+		if code == "" {
+			code = DefaultCurrency
+		}
+		if currency != "" && code != currency {
+			return "", fmt.Errorf("%s is priced in %s, the other materials in %s", material.Name, code, currency)
+		}
+		currency = code
+	}
+	if currency == "" {
+		return DefaultCurrency, nil
+	}
+
+	return currency, nil
+}
+
+// CarbonPerCubicMetre returns the embodied carbon of the material in
+// kgCO2e/m³, converting figures declared per kg with the density
+func (m Material) CarbonPerCubicMetre() float64 {
+	if m.GWPBasis == GWPPerCubicMetre {
+		return m.GWP
+	}
+
+	return m.GWP * m.Density
+}
+
+// HeatFlowDirection selects the surface resistances used by ISO 6946
+type HeatFlowDirection string
+
+const (
+	HeatFlowHorizontal HeatFlowDirection = "horizontal"
+	HeatFlowUpward     HeatFlowDirection = "upward"
+	HeatFlowDownward   HeatFlowDirection = "downward"
+)
+
 // New structs for insulation calculation
 type InsulationLayer struct {
-	Material  Material `json:"material"`
-	Thickness float64  `json:"thickness"`
-	UValue    float64  `json:"u_value"`
+	Material   Material  `json:"material"`
+	Thickness  float64   `json:"thickness"`        // mm
+	Lambda     float64   `json:"lambda"`           // W/mK used, the design value under LambdaConditions
+	Boards     []float64 `json:"boards,omitempty"` // stacked boards making up the thickness, mm
+	Resistance float64   `json:"resistance"`       // m²K/W
+	Carbon     float64   `json:"carbon"`           // embodied carbon, kgCO2e/m²
+
+	Bridge   *LayerBridge `json:"bridge,omitempty"`   // set for inhomogeneous layers
+	Air      *AirLayer    `json:"air,omitempty"`      // set for air gaps and cavities
+	Excluded bool         `json:"excluded,omitempty"` // outside a well-ventilated air layer, not counted
 }
 
+// LayerBridge is a second material running through a layer, e.g. the timber
+// studs in a layer of insulation
+type LayerBridge struct {
+	Material Material `json:"material"`
+	Fraction float64  `json:"fraction"` // area fraction of the bridging material, 0..1
+}
+
+// Bridged reports whether the layer is inhomogeneous
+func (l InsulationLayer) Bridged() bool {
+	return l.Bridge != nil && l.Bridge.Fraction > 0
+}
+
+// HeatCapacity returns ρc of the layer in J/m³K, area weighted for bridged
+// layers and 0 for air layers
+func (l InsulationLayer) HeatCapacity() float64 {
+	if l.Air != nil {
+		return 0
+	}
+	heat := l.Material.Density * l.Material.SpecificHeat
+	if l.Bridged() {
+		bridge := l.Bridge.Material.Density * l.Bridge.Material.SpecificHeat
+		heat = (1-l.Bridge.Fraction)*heat + l.Bridge.Fraction*bridge
+	}
+
+	return heat
+}
+
+// EquivalentAirThickness returns the sd-value of the layer in m
+func (l InsulationLayer) EquivalentAirThickness() float64 {
+	if l.Excluded {
+		return 0
+	}
+
+	return l.Material.Mu * l.Thickness / 1000
+}
+
+// VentilationClass classifies an air layer by the openings to the outside
+// per m of length (vertical layers) or m² of area (horizontal ones), ISO 6946
+// clause 6.9
+type VentilationClass string
+
+const (
+	Unventilated       VentilationClass = "unventilated" // up to 500 mm²
+	SlightlyVentilated VentilationClass = "slightly"     // 500 to 1500 mm²
+	WellVentilated     VentilationClass = "well"         // over 1500 mm²
+)
+
+// VentilationClasses lists the ventilation classes in the order they are shown
+var VentilationClasses = []VentilationClass{Unventilated, SlightlyVentilated, WellVentilated}
+
+// Label returns the name of the ventilation class shown to users
+func (v VentilationClass) Label() string {
+	switch v {
+	case SlightlyVentilated:
+		return "slightly ventilated"
+	case WellVentilated:
+		return "well ventilated"
+	default:
+		return "unventilated"
+	}
+}
+
+// AirLayer makes a layer an air gap. Its resistance depends on the heat flow
+// through the gap, empty meaning that of the construction.
+type AirLayer struct {
+	HeatFlow    HeatFlowDirection `json:"heat_flow,omitempty"`
+	Ventilation VentilationClass  `json:"ventilation"`
+}
+
+// AirMaterialID stands for an air layer where a material id is expected
+const AirMaterialID = "air"
+
+// AirMaterial is the material of air layers: no lambda, the resistance comes
+// from the ISO 6946 tables, and vapour passes freely
+func AirMaterial() Material {
+	return Material{Name: "Air layer", Mu: 1, Type: "air"}
+}
+
+// Ventilated reports whether the layer is a well-ventilated air layer, which
+// cuts off everything on its exterior side
+func (l InsulationLayer) Ventilated() bool {
+	return l.Air != nil && l.Air.Ventilation == WellVentilated
+}
+
+// InsulationResult holds a calculated build-up. BaseLayers are the existing
+// wall, Layers the insulation added on its exterior side.
 type InsulationResult struct {
-	Layers      []InsulationLayer `json:"layers"`
-	TotalUValue float64           `json:"total_u_value"`
-	TotalCost   float64           `json:"total_cost"`
+	BaseLayers         []InsulationLayer  `json:"base_layers"`
+	Layers             []InsulationLayer  `json:"layers"`
+	HeatFlow           HeatFlowDirection  `json:"heat_flow"`
+	InternalResistance float64            `json:"internal_resistance"` // Rsi
+	ExternalResistance float64            `json:"external_resistance"` // Rse
+	TotalResistance    float64            `json:"total_resistance"`
+	TotalUValue        float64            `json:"total_u_value"`
+	TotalCost          float64            `json:"total_cost"`
+	Currency           string             `json:"currency"`     // of TotalCost, from the prices of the added layers
+	TotalCarbon        float64            `json:"total_carbon"` // kgCO2e/m² of the added layers
+	Ground             *GroundFloor       `json:"ground,omitempty"`
+	GroundDetails      *GroundFloorResult `json:"ground_details,omitempty"`    // ISO 13370 steps when Ground is set
+	Bounds             *ResistanceBounds  `json:"bounds,omitempty"`            // ISO 6946 combined method when a layer is bridged
+	LambdaConditions   *LambdaConditions  `json:"lambda_conditions,omitempty"` // nil uses the declared lambdas
+
+	Fixings      *Fixings      `json:"fixings,omitempty"`
+	InvertedRoof *InvertedRoof `json:"inverted_roof,omitempty"`
+	Corrections  *UCorrections `json:"corrections,omitempty"` // ISO 6946 Annex F, set with Fixings or InvertedRoof
+
+	Temperature  *TemperatureProfile   `json:"temperature,omitempty"`
+	Dynamic      *DynamicProperties    `json:"dynamic,omitempty"`
+	Simulation   *Simulation           `json:"simulation,omitempty"`
+	Condensation *CondensationAnalysis `json:"condensation,omitempty"`
+	Lifecycle    *LifecycleAnalysis    `json:"lifecycle,omitempty"`
+	Compliance   *Compliance           `json:"compliance,omitempty"`
+	Junctions    *ElementJunctions     `json:"junctions,omitempty"` // set when junctions are placed along the element
+}
+
+// ResistanceBounds are the steps of the ISO 6946 combined method for
+// constructions with inhomogeneous layers, all resistances in m²K/W
+type ResistanceBounds struct {
+	Upper         float64             `json:"upper"`          // R'T, sections in parallel
+	Lower         float64             `json:"lower"`          // R''T, layers in series
+	RelativeError float64             `json:"relative_error"` // e = (R'T - R''T) / 2RT
+	Sections      []ResistanceSection `json:"sections"`
+}
+
+// ResistanceSection is one heat-flow path through the construction
+type ResistanceSection struct {
+	Fraction   float64 `json:"fraction"`   // share of the area, 0..1
+	Resistance float64 `json:"resistance"` // surface to surface, including Rsi and Rse
+}
+
+// Total returns RT, the mean of the upper and the lower bound
+func (b ResistanceBounds) Total() float64 {
+	return (b.Upper + b.Lower) / 2
+}
+
+// AddedThickness returns the thickness of the insulation layers in mm
+func (r InsulationResult) AddedThickness() float64 {
+	total := 0.0
+	for _, layer := range r.Layers {
+		total += layer.Thickness
+	}
+
+	return total
+}
+
+// AllLayers returns the base and insulation layers ordered from the interior
+// to the exterior
+func (r InsulationResult) AllLayers() []InsulationLayer {
+	layers := make([]InsulationLayer, 0, len(r.BaseLayers)+len(r.Layers))
+	layers = append(layers, r.BaseLayers...)
+	layers = append(layers, r.Layers...)
+
+	return layers
 }
 
 // TOMLData represents the structure of your TOML file
@@ -67,7 +394,8 @@ type Search struct {
 }
 
 func (t *Material) GetAllMaterials() ([]Material, error) {
-	query := fmt.Sprintf("SELECT id, name, description, lambda FROM materials WHERE created_by IN (%d, 1337) ORDER BY name DESC", t.CreatedBy)
+	query := fmt.Sprintf(`SELECT id, name, description, lambda, price, price_basis, price_thickness, package_coverage, currency, fire_class
+		FROM materials WHERE created_by IN (%d, 1337) ORDER BY name DESC`, t.CreatedBy)
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -78,7 +406,7 @@ func (t *Material) GetAllMaterials() ([]Material, error) {
 
 	Materials := []Material{}
 	for rows.Next() {
-		rows.Scan(&t.ID, &t.Name, &t.Description, &t.Lambda)
+		rows.Scan(&t.ID, &t.Name, &t.Description, &t.Lambda, &t.Price, &t.PriceBasis, &t.PriceThickness, &t.PackageCoverage, &t.Currency, &t.FireClass)
 
 		Materials = append(Materials, *t)
 	}
@@ -88,7 +416,8 @@ func (t *Material) GetAllMaterials() ([]Material, error) {
 
 func (t *Material) GetMaterialById() (Material, error) {
 
-	query := `SELECT id, name, description, lambda FROM materials
+	query := `SELECT id, name, description, lambda, price, price_basis, price_thickness, package_coverage, currency, mu, density, specific_heat, gwp, gwp_basis,
+		temperature_coefficient, moisture_coefficient, moisture_content, ageing_factor, fire_class, type FROM materials
 		WHERE created_by = ? AND id=?`
 
 	stmt, err := db.Prepare(query)
@@ -106,11 +435,32 @@ func (t *Material) GetMaterialById() (Material, error) {
 		&recoveredMaterial.Name,
 		&recoveredMaterial.Description,
 		&recoveredMaterial.Lambda,
+		&recoveredMaterial.Price,
+		&recoveredMaterial.PriceBasis,
+		&recoveredMaterial.PriceThickness,
+		&recoveredMaterial.PackageCoverage,
+		&recoveredMaterial.Currency,
+		&recoveredMaterial.Mu,
+		&recoveredMaterial.Density,
+		&recoveredMaterial.SpecificHeat,
+		&recoveredMaterial.GWP,
+		&recoveredMaterial.GWPBasis,
+		&recoveredMaterial.TemperatureCoefficient,
+		&recoveredMaterial.MoistureCoefficient,
+		&recoveredMaterial.MoistureContent,
+		&recoveredMaterial.AgeingFactor,
+		&recoveredMaterial.FireClass,
+		&recoveredMaterial.Type,
 	)
 	if err != nil {
 		return Material{}, err
 	}
 
+	recoveredMaterial.Thicknesses, err = GetMaterialThicknesses(recoveredMaterial.ID)
+	if err != nil {
+		return Material{}, err
+	}
+
 	return recoveredMaterial, nil
 }
 
@@ -158,8 +508,11 @@ func (t *Material) UpdateMaterial() (Material, error) {
 		return Material{}, errors.New("you cant update a system defined material 😭")
 	}
 
-	query := `UPDATE materials SET name = ?,  description = ?, status = ?, lambda = ?
-		WHERE created_by = ? AND id=? RETURNING id, name, description, lambda`
+	query := `UPDATE materials SET name = ?, description = ?, lambda = ?, price = ?, price_basis = ?, price_thickness = ?, package_coverage = ?, currency = ?,
+		mu = ?, density = ?, specific_heat = ?, gwp = ?, gwp_basis = ?,
+		temperature_coefficient = ?, moisture_coefficient = ?, moisture_content = ?, ageing_factor = ?, fire_class = ?, type = ?
+		WHERE created_by = ? AND id=? RETURNING id, name, description, lambda, price, price_basis, price_thickness, package_coverage, currency,
+		mu, density, specific_heat, gwp, gwp_basis, temperature_coefficient, moisture_coefficient, moisture_content, ageing_factor, fire_class, type`
 
 	stmt, err := db.Prepare(query)
 	if err != nil {
@@ -173,6 +526,22 @@ func (t *Material) UpdateMaterial() (Material, error) {
 		t.Name,
 		t.Description,
 		t.Lambda,
+		t.Price,
+		priceBasis(t.PriceBasis),
+		t.PriceThickness,
+		t.PackageCoverage,
+		currency(t.Currency),
+		t.Mu,
+		t.Density,
+		t.SpecificHeat,
+		t.GWP,
+		t.GWPBasis,
+		t.TemperatureCoefficient,
+		t.MoistureCoefficient,
+		t.MoistureContent,
+		t.AgeingFactor,
+		t.FireClass,
+		t.Type,
 		t.CreatedBy,
 		t.ID,
 	).Scan(
@@ -180,11 +549,33 @@ func (t *Material) UpdateMaterial() (Material, error) {
 		&updatedMaterial.Name,
 		&updatedMaterial.Description,
 		&updatedMaterial.Lambda,
+		&updatedMaterial.Price,
+		&updatedMaterial.PriceBasis,
+		&updatedMaterial.PriceThickness,
+		&updatedMaterial.PackageCoverage,
+		&updatedMaterial.Currency,
+		&updatedMaterial.Mu,
+		&updatedMaterial.Density,
+		&updatedMaterial.SpecificHeat,
+		&updatedMaterial.GWP,
+		&updatedMaterial.GWPBasis,
+		&updatedMaterial.TemperatureCoefficient,
+		&updatedMaterial.MoistureCoefficient,
+		&updatedMaterial.MoistureContent,
+		&updatedMaterial.AgeingFactor,
+		&updatedMaterial.FireClass,
+		&updatedMaterial.Type,
 	)
 	if err != nil {
 		return Material{}, err
 	}
 
+	err = SetMaterialThicknesses(updatedMaterial.ID, t.Thicknesses)
+	if err != nil {
+		return Material{}, err
+	}
+	updatedMaterial.Thicknesses = t.Thicknesses
+
 	return updatedMaterial, nil
 }
 
@@ -194,6 +585,16 @@ func (t *Material) DeleteMaterial() error {
 		return errors.New("you cant delete a system defined material 😭")
 	}
 
+	var uses int
+	err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM building_element_layers WHERE material_id = ?)
+		+ (SELECT COUNT(*) FROM building_element_layer_bridges WHERE material_id = ?)`, t.ID, t.ID).Scan(&uses)
+	if err != nil {
+		return err
+	}
+	if uses > 0 {
+		return fmt.Errorf("the material is used by %d layer(s) of building elements", uses)
+	}
+
 	query := `DELETE FROM materials
 		WHERE created_by = ? AND id=?`
 
@@ -213,11 +614,12 @@ func (t *Material) DeleteMaterial() error {
 		return errors.New("an affected row was expected")
 	}
 
-	return nil
+	return SetMaterialThicknesses(t.ID, nil)
 }
 
 func (t *Material) SearchMaterial(search Search) ([]Material, error) {
-	query := "SELECT id, name, description, lambda FROM materials WHERE created_by IN (?, 1337)"
+	query := `SELECT id, name, description, lambda, price, price_basis, price_thickness, package_coverage, currency, fire_class
+		FROM materials WHERE created_by IN (?, 1337)`
 
 	args := []interface{}{t.CreatedBy}
 
@@ -231,11 +633,6 @@ func (t *Material) SearchMaterial(search Search) ([]Material, error) {
 		args = append(args, search.Lambda)
 	}
 
-	if search.Price != 0 {
-		query += " AND price < ?"
-		args = append(args, search.Price)
-	}
-
 	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
@@ -245,10 +642,15 @@ func (t *Material) SearchMaterial(search Search) ([]Material, error) {
 	materials := []Material{}
 	for rows.Next() {
 		var material Material
-		err := rows.Scan(&material.ID, &material.Name, &material.Description, &material.Lambda)
+		err := rows.Scan(&material.ID, &material.Name, &material.Description, &material.Lambda,
+			&material.Price, &material.PriceBasis, &material.PriceThickness, &material.PackageCoverage, &material.Currency, &material.FireClass)
 		if err != nil {
 			return nil, err
 		}
+		// prices are quoted on different bases, so compare them per m³
+		if search.Price != 0 && material.PricePerCubicMetre() >= search.Price {
+			continue
+		}
 		materials = append(materials, material)
 	}
 
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/kaloszer/insulationCalcHtmx/units"
+)
+
+// Objective is what the optimizer minimises besides U-value and thickness
+type Objective string
+
+const (
+	ObjectiveCost     Objective = "cost"
+	ObjectiveCarbon   Objective = "carbon"
+	ObjectiveWeighted Objective = "weighted" // cost plus carbon priced per kgCO2e
+)
+
+// Score returns the value of the objective for a build-up with the given
+// cost and embodied carbon
+func (o Objective) Score(cost, carbon, carbonPrice float64) float64 {
+	switch o {
+	case ObjectiveCarbon:
+		return carbon
+	case ObjectiveWeighted:
+		return cost + carbonPrice*carbon
+	default:
+		return cost
+	}
+}
+
+// OptimizationResult is the Pareto front of insulation options for one
+// construction: no solution on it is beaten on the objective, U-value and
+// added thickness at the same time by another.
+type OptimizationResult struct {
+	TargetUValue float64            `json:"target_u_value"`
+	MaxThickness float64            `json:"max_thickness"` // mm, 0 for no limit
+	Objective    Objective          `json:"objective"`
+	CarbonPrice  float64            `json:"carbon_price"` // per kgCO2e, for the weighted objective
+	Currency     string             `json:"currency"`     // of the costs and CarbonPrice
+	MinFireClass FireClass          `json:"min_fire_class,omitempty"`
+	Excluded     []string           `json:"excluded,omitempty"`     // materials below MinFireClass
+	MinTimeLag   float64            `json:"min_time_lag,omitempty"` // h, ISO 13786 time shift the best solution needs
+	Front        []InsulationResult `json:"front"`                  // sorted by score
+	Best         int                `json:"best"`                   // lowest scoring solution meeting the target, -1 if none does
+	Candidates   int                `json:"candidates"`
+}
+
+// Recommended returns the best solution, or the one with the lowest U-value
+// when the target cannot be met
+func (o OptimizationResult) Recommended() InsulationResult {
+	if o.Best >= 0 {
+		return o.Front[o.Best]
+	}
+
+	lowest := 0
+	for i, result := range o.Front {
+		if result.TotalUValue < o.Front[lowest].TotalUValue {
+			lowest = i
+		}
+	}
+
+	return o.Front[lowest]
+}
+
+// Score returns the objective value of a solution
+func (o OptimizationResult) Score(result InsulationResult) float64 {
+	return o.Objective.Score(result.TotalCost, result.TotalCarbon, o.CarbonPrice)
+}
+
+// Infeasible reports whether no solution meets the target U-value
+func (o OptimizationResult) Infeasible() bool {
+	return o.Best < 0
+}
+
+// Explanation says why the target cannot be met and what is achievable
+// instead. It is empty when the target is met.
+func (o OptimizationResult) Explanation(system units.System) string {
+	if !o.Infeasible() {
+		return ""
+	}
+
+	decimals, thicknessDecimals := 3, 0
+	if system != units.Metric {
+		decimals, thicknessDecimals = 4, 2
+	}
+	target := system.Format(units.UValue, o.TargetUValue, decimals)
+	if o.MinTimeLag > 0 {
+		target += fmt.Sprintf(" with a time shift of %.1f h", o.MinTimeLag)
+	}
+	best := o.Recommended()
+	lowest := system.Format(units.UValue, best.TotalUValue, decimals)
+	added := system.Format(units.Thickness, best.AddedThickness(), thicknessDecimals)
+	limit := system.Format(units.Thickness, o.MaxThickness, thicknessDecimals)
+	if o.MaxThickness <= 0 {
+		return fmt.Sprintf("No combination of the selected materials reaches U = %s. The lowest achievable is U = %s with %s of insulation.",
+			target, lowest, added)
+	}
+	if len(best.Layers) == 0 {
+		return fmt.Sprintf("None of the selected materials fits within the maximum added thickness of %s, so U = %s cannot be reached. The construction stays at U = %s.",
+			limit, target, lowest)
+	}
+
+	return fmt.Sprintf("U = %s cannot be reached within the maximum added thickness of %s. The lowest achievable is U = %s with %s of insulation.",
+		target, limit, lowest, added)
+}
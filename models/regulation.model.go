@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Regulation is a set of national maximum U-values, one per element type
+type Regulation struct {
+	ID     string             `json:"id" toml:"id"`
+	Name   string             `json:"name" toml:"name"`
+	Limits map[string]float64 `json:"limits" toml:"limits"` // W/m²K, keyed by ElementType
+}
+
+// Limit returns the maximum U-value for the element type, if the regulation
+// sets one
+func (r Regulation) Limit(elementType ElementType) (float64, bool) {
+	limit, ok := r.Limits[string(elementType)]
+
+	return limit, ok
+}
+
+// Thresholds returns the distinct limits set for the given element types,
+// lowest first
+func (r Regulation) Thresholds(elementTypes []ElementType) []float64 {
+	thresholds := []float64{}
+	seen := map[float64]bool{}
+	for _, elementType := range elementTypes {
+		if limit, ok := r.Limit(elementType); ok && !seen[limit] {
+			seen[limit] = true
+			thresholds = append(thresholds, limit)
+		}
+	}
+	sort.Float64s(thresholds)
+
+	return thresholds
+}
+
+// Check compares a U-value with the limit for the element type. It returns
+// false when the regulation sets no limit for it.
+func (r Regulation) Check(elementType ElementType, uValue float64) (Compliance, bool) {
+	limit, ok := r.Limit(elementType)
+	if !ok {
+		return Compliance{}, false
+	}
+
+	return Compliance{
+		Regulation:  r,
+		ElementType: elementType,
+		Limit:       limit,
+		UValue:      uValue,
+	}, true
+}
+
+// Compliance is the result of checking a U-value against a regulation
+type Compliance struct {
+	Regulation  Regulation  `json:"regulation"`
+	ElementType ElementType `json:"element_type"`
+	Limit       float64     `json:"limit"`   // W/m²K
+	UValue      float64     `json:"u_value"` // W/m²K
+}
+
+// Passes reports whether the U-value is at or below the limit
+func (c Compliance) Passes() bool {
+	return c.UValue <= c.Limit
+}
+
+// Margin is how far the U-value is below the limit, negative when it fails
+func (c Compliance) Margin() float64 {
+	return c.Limit - c.UValue
+}
+
+// RegulationTOMLData represents the structure of the regulations TOML file
+type RegulationTOMLData struct {
+	Regulations []Regulation `toml:"regulation"`
+}
+
+// LoadRegulationsFromTOML loads the bundled regulation limits
+func LoadRegulationsFromTOML(filename string) ([]Regulation, error) {
+	var data RegulationTOMLData
+
+	if _, err := toml.DecodeFile(filename, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode regulations from TOML file: %w", err)
+	}
+
+	for _, regulation := range data.Regulations {
+		for key, limit := range regulation.Limits {
+			known := false
+			for _, elementType := range ElementTypes {
+				known = known || key == string(elementType)
+			}
+			if !known || limit <= 0 {
+				return nil, fmt.Errorf("regulation %q has an invalid limit for %q", regulation.ID, key)
+			}
+		}
+	}
+
+	return data.Regulations, nil
+}
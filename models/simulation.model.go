@@ -0,0 +1,32 @@
+package models
+
+// SimulationInputs are the boundary conditions of a transient simulation:
+// a constant indoor temperature and an hourly outdoor temperature series
+type SimulationInputs struct {
+	IndoorTemperature   float64   `json:"indoor_temperature"`   // °C
+	OutdoorTemperatures []float64 `json:"outdoor_temperatures"` // °C, one per hour
+}
+
+// SimulationNode is a point of the discretised construction, the centre of
+// a control volume
+type SimulationNode struct {
+	Position float64 `json:"position"` // mm from the interior surface
+	Layer    string  `json:"layer"`
+}
+
+// SimulationStep holds the state at the end of an hour
+type SimulationStep struct {
+	Hour               int       `json:"hour"`
+	OutdoorTemperature float64   `json:"outdoor_temperature"` // °C
+	SurfaceTemperature float64   `json:"surface_temperature"` // interior surface, °C
+	HeatFlux           float64   `json:"heat_flux"`           // W/m² leaving the room through the interior surface
+	Temperatures       []float64 `json:"temperatures"`        // °C, one per node
+}
+
+// Simulation is the response of a construction to the outdoor series, hour 0
+// being the steady state at the first outdoor temperature
+type Simulation struct {
+	Inputs SimulationInputs `json:"inputs"`
+	Nodes  []SimulationNode `json:"nodes"`
+	Steps  []SimulationStep `json:"steps"`
+}
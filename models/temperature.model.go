@@ -0,0 +1,17 @@
+package models
+
+// TemperaturePoint is the steady-state temperature at a layer boundary
+type TemperaturePoint struct {
+	Position    float64 `json:"position"`    // mm from the interior surface
+	Temperature float64 `json:"temperature"` // °C
+}
+
+// TemperatureProfile holds the interface temperatures of a build-up and the
+// interior surface temperature factor used for the mould check (ISO 13788)
+type TemperatureProfile struct {
+	Conditions DesignConditions   `json:"conditions"`
+	Boundaries []TemperaturePoint `json:"boundaries"` // interior surface first, exterior surface last
+	FRsi       float64            `json:"f_rsi"`
+	FRsiMin    float64            `json:"f_rsi_min"`
+	MouldRisk  bool               `json:"mould_risk"`
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// MaxBridgeCells limits the size of the grid of a thermal bridge geometry
+const MaxBridgeCells = 40000
+
+// BridgeGeometry is a 2D section through a junction, drawn as rectangles on
+// a grid of square cells. Lengths are in mm, x from the left and y from the
+// top of the section.
+type BridgeGeometry struct {
+	Name         string              `json:"name" toml:"name"`
+	Description  string              `json:"description,omitempty" toml:"description"`
+	Cell         float64             `json:"cell" toml:"cell"` // edge of a grid cell, mm
+	Width        float64             `json:"width" toml:"width"`
+	Height       float64             `json:"height" toml:"height"`
+	Environments []BridgeEnvironment `json:"environments" toml:"environment"`
+	Regions      []BridgeRegion      `json:"regions" toml:"region"`
+	Flanking     []FlankingElement   `json:"flanking,omitempty" toml:"flanking"`
+}
+
+// BridgeEnvironment is the air on one side of the junction with the surface
+// resistance between the air and the faces it touches
+type BridgeEnvironment struct {
+	Name        string  `json:"name" toml:"name"`
+	Temperature float64 `json:"temperature" toml:"temperature"` // °C
+	Resistance  float64 `json:"resistance" toml:"resistance"`   // m²K/W
+}
+
+// BridgeRegion fills a rectangle with a library material or an environment.
+// Later regions are drawn over earlier ones and cells no region covers are
+// adiabatic, like the cut-off planes of ISO 10211.
+type BridgeRegion struct {
+	Material    string  `json:"material,omitempty" toml:"material"`
+	Environment string  `json:"environment,omitempty" toml:"environment"`
+	X           float64 `json:"x" toml:"x"`
+	Y           float64 `json:"y" toml:"y"`
+	Width       float64 `json:"width" toml:"width"`
+	Height      float64 `json:"height" toml:"height"`
+}
+
+// FlankingElement is a plane element of the junction whose one-dimensional
+// heat loss U·l is subtracted from the coupling coefficient to give ψ
+type FlankingElement struct {
+	Name   string  `json:"name" toml:"name"`
+	UValue float64 `json:"u_value" toml:"u_value"` // W/m²K
+	Length float64 `json:"length" toml:"length"`   // mm
+}
+
+// BridgeExample is a bundled geometry file
+type BridgeExample struct {
+	File     string
+	Name     string
+	Contents string
+}
+
+// Columns is the number of cells across the section
+func (g BridgeGeometry) Columns() int {
+	return int(math.Round(g.Width / g.Cell))
+}
+
+// Rows is the number of cells down the section
+func (g BridgeGeometry) Rows() int {
+	return int(math.Round(g.Height / g.Cell))
+}
+
+// Environment finds an environment by name
+func (g BridgeGeometry) Environment(name string) (int, bool) {
+	for i, environment := range g.Environments {
+		if environment.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Temperatures returns the warm and cold temperatures of the environments
+func (g BridgeGeometry) Temperatures() (warm, cold float64) {
+	warm, cold = math.Inf(-1), math.Inf(1)
+	for _, environment := range g.Environments {
+		warm = math.Max(warm, environment.Temperature)
+		cold = math.Min(cold, environment.Temperature)
+	}
+	return warm, cold
+}
+
+// Validate checks the grid size, the environments and that every region
+// fills its rectangle with exactly one material or environment
+func (g BridgeGeometry) Validate() error {
+	for _, value := range []float64{g.Cell, g.Width, g.Height} {
+		if math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 {
+			return fmt.Errorf("cell, width and height must be positive")
+		}
+	}
+	// Check each side before multiplying, so huge sides cannot overflow
+	for _, cells := range []float64{g.Width / g.Cell, g.Height / g.Cell} {
+		if math.Round(cells) < 1 || math.Round(cells) > MaxBridgeCells {
+			return fmt.Errorf("the grid needs 1 to %d cells along each side", MaxBridgeCells)
+		}
+	}
+	if g.Columns()*g.Rows() > MaxBridgeCells {
+		return fmt.Errorf("the grid has %d cells, use a larger cell to stay within %d", g.Columns()*g.Rows(), MaxBridgeCells)
+	}
+
+	temperatures := map[float64]bool{}
+	names := map[string]bool{}
+	for _, environment := range g.Environments {
+		if environment.Name == "" || names[environment.Name] {
+			return fmt.Errorf("every environment needs a unique name")
+		}
+		if environment.Resistance < 0 {
+			return fmt.Errorf("environment %q has a negative surface resistance", environment.Name)
+		}
+		names[environment.Name] = true
+		temperatures[environment.Temperature] = true
+	}
+	if len(temperatures) != 2 {
+		return fmt.Errorf("the environments need exactly two different temperatures, got %d", len(temperatures))
+	}
+
+	for i, region := range g.Regions {
+		if (region.Material == "") == (region.Environment == "") {
+			return fmt.Errorf("region %d needs either a material or an environment", i+1)
+		}
+		if region.Environment != "" && !names[region.Environment] {
+			return fmt.Errorf("region %d uses the unknown environment %q", i+1, region.Environment)
+		}
+		if region.Width <= 0 || region.Height <= 0 {
+			return fmt.Errorf("region %d needs a positive width and height", i+1)
+		}
+	}
+
+	for _, element := range g.Flanking {
+		if element.UValue < 0 || element.Length < 0 {
+			return fmt.Errorf("flanking element %q needs a positive U-value and length", element.Name)
+		}
+	}
+
+	return nil
+}
+
+// ParseBridgeGeometry reads and validates a geometry in TOML
+func ParseBridgeGeometry(contents string) (BridgeGeometry, error) {
+	var geometry BridgeGeometry
+
+	if _, err := toml.Decode(contents, &geometry); err != nil {
+		return geometry, fmt.Errorf("failed to decode geometry: %w", err)
+	}
+
+	return geometry, geometry.Validate()
+}
+
+// LoadBridgeExamples reads the bundled geometries of a directory, sorted by
+// file name
+func LoadBridgeExamples(dir string) ([]BridgeExample, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	examples := make([]BridgeExample, 0, len(files))
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read thermal bridge example: %w", err)
+		}
+		geometry, err := ParseBridgeGeometry(string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("thermal bridge example %s: %w", filepath.Base(file), err)
+		}
+		examples = append(examples, BridgeExample{
+			File:     strings.TrimSuffix(filepath.Base(file), ".toml"),
+			Name:     geometry.Name,
+			Contents: string(contents),
+		})
+	}
+
+	return examples, nil
+}
+
+// ThermalBridgeResult is the steady-state temperature field of a junction
+// per metre of its length, with its ISO 10211 coupling coefficient L2D,
+// linear thermal transmittance ψ = L2D - Σ U·l and the lowest temperature of
+// the warm surfaces as the temperature factor fRsi.
+type ThermalBridgeResult struct {
+	Geometry     BridgeGeometry `json:"geometry"`
+	Materials    []string       `json:"materials"`    // names of the materials used in the grid
+	Cells        [][]int        `json:"cells"`        // [row][column] material index, -1 for voids, -2-i for environment i
+	Temperatures [][]float64    `json:"temperatures"` // [row][column] °C of material cells
+
+	HeatFlow       float64 `json:"heat_flow"`       // W/m from the warm to the cold side
+	Coupling       float64 `json:"coupling"`        // L2D, W/mK
+	FlankingLosses float64 `json:"flanking_losses"` // Σ U·l, W/mK
+	Psi            float64 `json:"psi"`             // W/mK
+
+	MinSurfaceTemperature float64 `json:"min_surface_temperature"` // °C
+	MinSurfaceX           float64 `json:"min_surface_x"`           // mm
+	MinSurfaceY           float64 `json:"min_surface_y"`           // mm
+	FRsi                  float64 `json:"f_rsi"`
+	Iterations            int     `json:"iterations"`
+}
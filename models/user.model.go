@@ -1,6 +1,10 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
+
+	"github.com/kaloszer/insulationCalcHtmx/units"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -71,3 +75,30 @@ func CheckEmail(email string) (User, error) {
 
 	return user, nil
 }
+
+// GetUnitSystem returns the unit system the user prefers, metric until they
+// choose one
+func GetUnitSystem(userId uint64) (units.System, error) {
+	query := `SELECT unit_system FROM user_preferences WHERE user_id=$1`
+
+	var system string
+	err := db.QueryRow(query, userId).Scan(&system)
+	if errors.Is(err, sql.ErrNoRows) {
+		return units.Metric, nil
+	}
+	if err != nil {
+		return units.Metric, err
+	}
+
+	return units.ParseSystem(system)
+}
+
+// SetUnitSystem stores the unit system the user prefers
+func SetUnitSystem(userId uint64, system units.System) error {
+	stmt := `INSERT INTO user_preferences(user_id, unit_system) VALUES($1, $2)
+		ON CONFLICT(user_id) DO UPDATE SET unit_system=excluded.unit_system`
+
+	_, err := db.Exec(stmt, userId, string(system))
+
+	return err
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WindowKind tells windows and doors apart
+type WindowKind string
+
+const (
+	WindowKindWindow WindowKind = "window"
+	WindowKindDoor   WindowKind = "door"
+)
+
+// WindowProduct is a window or door of the user's library. The frame is
+// taken as equally wide on all sides. For doors the infill is a glazing unit
+// or an opaque panel, described by Ug and PsiG in the same way.
+type WindowProduct struct {
+	ID          uint64     `json:"id"`
+	CreatedBy   uint64     `json:"created_by"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Kind        WindowKind `json:"kind"`
+	Width       float64    `json:"width"`       // overall, mm
+	Height      float64    `json:"height"`      // overall, mm
+	FrameWidth  float64    `json:"frame_width"` // visible frame width, mm
+	Ug          float64    `json:"ug"`          // glazing or panel, W/m²K
+	Uf          float64    `json:"uf"`          // frame, W/m²K
+	PsiG        float64    `json:"psi_g"`       // spacer or panel edge, W/mK
+	GValue      float64    `json:"g_value"`     // total solar energy transmittance of the glazing, 0..1
+}
+
+// WindowUValue holds the areas and the result of EN ISO 10077-1
+type WindowUValue struct {
+	Area          float64 `json:"area"`           // Aw, m²
+	GlazingArea   float64 `json:"glazing_area"`   // Ag, m²
+	FrameArea     float64 `json:"frame_area"`     // Af, m²
+	GlazingEdge   float64 `json:"glazing_edge"`   // lg, m
+	UValue        float64 `json:"u_value"`        // Uw, W/m²K
+	GlazingFactor float64 `json:"glazing_factor"` // Ag/Aw
+}
+
+func (w *WindowProduct) GetAllWindows() ([]WindowProduct, error) {
+	query := `SELECT id, created_by, name, description, kind, width, height, frame_width, ug, uf, psi_g, g_value
+		FROM windows WHERE created_by = ? ORDER BY id DESC`
+
+	rows, err := db.Query(query, w.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("error querying windows: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []WindowProduct{}
+	for rows.Next() {
+		var p WindowProduct
+		err := rows.Scan(&p.ID, &p.CreatedBy, &p.Name, &p.Description, &p.Kind,
+			&p.Width, &p.Height, &p.FrameWidth, &p.Ug, &p.Uf, &p.PsiG, &p.GValue)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning window row: %w", err)
+		}
+		windows = append(windows, p)
+	}
+
+	return windows, nil
+}
+
+func (w *WindowProduct) GetWindowById() (WindowProduct, error) {
+	query := `SELECT id, created_by, name, description, kind, width, height, frame_width, ug, uf, psi_g, g_value
+		FROM windows WHERE created_by = ? AND id = ?`
+
+	var p WindowProduct
+	err := db.QueryRow(query, w.CreatedBy, w.ID).Scan(
+		&p.ID,
+		&p.CreatedBy,
+		&p.Name,
+		&p.Description,
+		&p.Kind,
+		&p.Width,
+		&p.Height,
+		&p.FrameWidth,
+		&p.Ug,
+		&p.Uf,
+		&p.PsiG,
+		&p.GValue,
+	)
+	if err != nil {
+		return WindowProduct{}, err
+	}
+
+	return p, nil
+}
+
+func (w *WindowProduct) CreateWindow() error {
+	query := `INSERT INTO windows (created_by, name, description, kind, width, height, frame_width, ug, uf, psi_g, g_value)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := db.Exec(query, w.CreatedBy, w.Name, w.Description, w.Kind,
+		w.Width, w.Height, w.FrameWidth, w.Ug, w.Uf, w.PsiG, w.GValue)
+	if err != nil {
+		return fmt.Errorf("error adding window: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error adding window: %w", err)
+	}
+	w.ID = uint64(id)
+
+	return nil
+}
+
+func (w *WindowProduct) UpdateWindow() error {
+	query := `UPDATE windows SET name = ?, description = ?, kind = ?, width = ?, height = ?, frame_width = ?, ug = ?, uf = ?, psi_g = ?, g_value = ?
+		WHERE created_by = ? AND id = ?`
+
+	result, err := db.Exec(query, w.Name, w.Description, w.Kind, w.Width, w.Height,
+		w.FrameWidth, w.Ug, w.Uf, w.PsiG, w.GValue, w.CreatedBy, w.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	return nil
+}
+
+// DeleteWindow removes a window product unless a building element uses it
+func (w *WindowProduct) DeleteWindow() error {
+	var uses int
+	err := db.QueryRow(`SELECT COUNT(*) FROM element_windows WHERE window_id = ?`, w.ID).Scan(&uses)
+	if err != nil {
+		return err
+	}
+	if uses > 0 {
+		return fmt.Errorf("the window is used by %d building element(s)", uses)
+	}
+
+	result, err := db.Exec(`DELETE FROM windows WHERE created_by = ? AND id = ?`, w.CreatedBy, w.ID)
+	if err != nil {
+		return err
+	}
+
+	if i, err := result.RowsAffected(); err != nil || i != 1 {
+		return errors.New("an affected row was expected")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/units"
+)
+
+// WriteCSV writes every hour of the simulation with the outdoor and interior
+// surface temperatures, the heat loss and the temperature of every node, in
+// the unit system
+func WriteCSV(w io.Writer, simulation models.Simulation, system units.System) error {
+	writer := csv.NewWriter(w)
+
+	temperature := system.Symbol(units.Temperature)
+	header := []string{"hour", "outdoor (" + temperature + ")", "interior surface (" + temperature + ")", "heat loss (" + system.Symbol(units.HeatFlux) + ")"}
+	for _, node := range simulation.Nodes {
+		header = append(header, fmt.Sprintf("%s at %s (%s)", node.Layer, system.Format(units.Thickness, node.Position, 1), temperature))
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, step := range simulation.Steps {
+		row := []string{
+			fmt.Sprint(step.Hour),
+			fmt.Sprintf("%.2f", system.FromMetric(units.Temperature, step.OutdoorTemperature)),
+			fmt.Sprintf("%.2f", system.FromMetric(units.Temperature, step.SurfaceTemperature)),
+			fmt.Sprintf("%.3f", system.FromMetric(units.HeatFlux, step.HeatFlux)),
+		}
+		for _, value := range step.Temperatures {
+			row = append(row, fmt.Sprintf("%.2f", system.FromMetric(units.Temperature, value)))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,45 @@
+package simulation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+	"github.com/kaloszer/insulationCalcHtmx/units"
+)
+
+func TestWriteCSV(t *testing.T) {
+	simulation := models.Simulation{
+		Nodes: []models.SimulationNode{{Position: 25.4, Layer: "Concrete"}},
+		Steps: []models.SimulationStep{
+			{Hour: 0, OutdoorTemperature: -10, SurfaceTemperature: 18, HeatFlux: 10, Temperatures: []float64{0}},
+		},
+	}
+	tests := []struct {
+		system units.System
+		want   string
+	}{
+		{
+			system: units.Metric,
+			want: "hour,outdoor (°C),interior surface (°C),heat loss (W/m²),Concrete at 25.4 mm (°C)\n" +
+				"0,-10.00,18.00,10.000,0.00\n",
+		},
+		{
+			system: units.Imperial,
+			want: "hour,outdoor (°F),interior surface (°F),heat loss (BTU/h·ft²),Concrete at 1.0 in (°F)\n" +
+				"0,14.00,64.40,3.170,32.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.system), func(t *testing.T) {
+			var out strings.Builder
+			if err := WriteCSV(&out, simulation, tt.system); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Package simulation runs transient heat transfer simulations of a
+// construction, where the calculations package works in the steady state or
+// with periodic ISO 13786 properties.
+package simulation
+
+import (
+	"math"
+
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// MaxCellThickness is the thickest control volume a layer is split into, mm
+const MaxCellThickness = 10.0
+
+// StepsPerHour is the number of implicit time steps taken every hour
+const StepsPerHour = 6
+
+// MaxHours limits the outdoor temperature series to a month
+const MaxHours = 744
+
+// grid is a construction discretised into control volumes: node i holds the
+// heat capacity Capacity[i] in J/m²K and is linked to node i+1 by the
+// resistance Links[i+1] in m²K/W. Links[0] joins the indoor air to the first
+// node and the last link the last node to the outdoor air.
+type grid struct {
+	Nodes    []models.SimulationNode
+	Capacity []float64
+	Links    []float64
+}
+
+// discretise splits every layer with a heat capacity into cells of at most
+// MaxCellThickness, at least two per layer. Air layers and layers without a
+// density or specific heat only add their resistance to the link they sit
+// on, like the surface resistances.
+func discretise(result models.InsulationResult) grid {
+	g := grid{}
+	pending := result.InternalResistance
+	position := 0.0
+	for _, layer := range result.AllLayers() {
+		if layer.Excluded {
+			continue
+		}
+		heat := layer.HeatCapacity()
+		if heat <= 0 || layer.Thickness <= 0 || layer.Resistance <= 0 {
+			pending += layer.Resistance
+			position += layer.Thickness
+			continue
+		}
+
+		cells := int(math.Max(2, math.Ceil(layer.Thickness/MaxCellThickness)))
+		thickness := layer.Thickness / float64(cells)
+		resistance := layer.Resistance / float64(cells)
+		for i := 0; i < cells; i++ {
+			g.Links = append(g.Links, pending+resistance/2)
+			g.Capacity = append(g.Capacity, heat*thickness/1000)
+			g.Nodes = append(g.Nodes, models.SimulationNode{
+				Position: position + thickness*(float64(i)+0.5),
+				Layer:    layer.Material.Name,
+			})
+			pending = resistance / 2
+		}
+		position += layer.Thickness
+	}
+	g.Links = append(g.Links, pending+result.ExternalResistance)
+
+	return g
+}
+
+// steadyState returns the node temperatures for constant indoor and outdoor
+// temperatures
+func (g grid) steadyState(indoor, outdoor float64) []float64 {
+	total := 0.0
+	for _, link := range g.Links {
+		total += link
+	}
+
+	temperatures := make([]float64, len(g.Nodes))
+	resistance := 0.0
+	for i := range g.Nodes {
+		resistance += g.Links[i]
+		temperatures[i] = indoor - (indoor-outdoor)*resistance/total
+	}
+
+	return temperatures
+}
+
+// step advances the node temperatures by dt seconds with the implicit
+// (backward Euler) scheme
+//
+//	Ci (Ti' - Ti)/dt = (Ti-1' - Ti')/Ri + (Ti+1' - Ti')/Ri+1,
+//
+// which is stable for any dt. The tridiagonal system is solved with the
+// Thomas algorithm.
+func (g grid) step(temperatures []float64, indoor, outdoor, dt float64) []float64 {
+	n := len(temperatures)
+	lower, diagonal, upper, rhs := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range temperatures {
+		left, right := 1/g.Links[i], 1/g.Links[i+1]
+		storage := g.Capacity[i] / dt
+		lower[i], upper[i] = -left, -right
+		diagonal[i] = storage + left + right
+		rhs[i] = storage * temperatures[i]
+	}
+	rhs[0] += indoor / g.Links[0]
+	rhs[n-1] += outdoor / g.Links[n]
+
+	for i := 1; i < n; i++ {
+		m := lower[i] / diagonal[i-1]
+		diagonal[i] -= m * upper[i-1]
+		rhs[i] -= m * rhs[i-1]
+	}
+	next := make([]float64, n)
+	next[n-1] = rhs[n-1] / diagonal[n-1]
+	for i := n - 2; i >= 0; i-- {
+		next[i] = (rhs[i] - upper[i]*next[i+1]) / diagonal[i]
+	}
+
+	return next
+}
+
+// record returns the state of the grid at the end of an hour
+func (g grid) record(hour int, temperatures []float64, result models.InsulationResult, indoor, outdoor float64) models.SimulationStep {
+	step := models.SimulationStep{
+		Hour:               hour,
+		OutdoorTemperature: outdoor,
+		Temperatures:       temperatures,
+	}
+	if len(temperatures) == 0 {
+		// Without heat capacity the construction follows the outdoor air at once
+		step.HeatFlux = (indoor - outdoor) / g.Links[0]
+	} else {
+		step.HeatFlux = (indoor - temperatures[0]) / g.Links[0]
+	}
+	step.SurfaceTemperature = indoor - step.HeatFlux*result.InternalResistance
+
+	return step
+}
+
+// Run simulates the response of a calculated build-up to the hourly outdoor
+// series, starting from the steady state at the first outdoor temperature.
+// Within an hour the outdoor temperature changes linearly.
+func Run(result models.InsulationResult, inputs models.SimulationInputs) models.Simulation {
+	g := discretise(result)
+	simulation := models.Simulation{
+		Inputs: inputs,
+		Nodes:  g.Nodes,
+		Steps:  make([]models.SimulationStep, 0, len(inputs.OutdoorTemperatures)),
+	}
+	if len(inputs.OutdoorTemperatures) == 0 {
+		return simulation
+	}
+
+	indoor := inputs.IndoorTemperature
+	outdoor := inputs.OutdoorTemperatures
+	temperatures := g.steadyState(indoor, outdoor[0])
+	simulation.Steps = append(simulation.Steps, g.record(0, temperatures, result, indoor, outdoor[0]))
+
+	dt := 3600.0 / StepsPerHour
+	for hour := 1; hour < len(outdoor); hour++ {
+		for s := 1; s <= StepsPerHour && len(temperatures) > 0; s++ {
+			current := outdoor[hour-1] + (outdoor[hour]-outdoor[hour-1])*float64(s)/StepsPerHour
+			temperatures = g.step(temperatures, indoor, current, dt)
+		}
+		simulation.Steps = append(simulation.Steps, g.record(hour, temperatures, result, indoor, outdoor[hour]))
+	}
+
+	return simulation
+}
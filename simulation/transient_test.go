@@ -0,0 +1,95 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kaloszer/insulationCalcHtmx/calculations"
+	"github.com/kaloszer/insulationCalcHtmx/models"
+)
+
+// concrete is 200 mm of concrete in a wall, RT = 0.13 + 0.1 + 0.04
+func concrete() models.InsulationResult {
+	return calculations.Evaluate(models.InsulationResult{
+		BaseLayers: []models.InsulationLayer{{
+			Material:  models.Material{Name: "Concrete", Lambda: 2.0, Density: 2400, SpecificHeat: 1000},
+			Thickness: 200,
+		}},
+		HeatFlow: models.HeatFlowHorizontal,
+	})
+}
+
+func TestRunSteadyState(t *testing.T) {
+	// A constant outdoor temperature keeps the steady state, q = U·ΔT
+	result := concrete()
+	outdoor := make([]float64, 48)
+	for i := range outdoor {
+		outdoor[i] = -10
+	}
+	simulation := Run(result, models.SimulationInputs{IndoorTemperature: 20, OutdoorTemperatures: outdoor})
+
+	if len(simulation.Steps) != len(outdoor) {
+		t.Fatalf("got %d steps, want %d", len(simulation.Steps), len(outdoor))
+	}
+	if len(simulation.Nodes) != 20 {
+		t.Errorf("got %d nodes, want 20 cells of 10 mm", len(simulation.Nodes))
+	}
+	for _, step := range []models.SimulationStep{simulation.Steps[0], simulation.Steps[len(outdoor)-1]} {
+		if math.Abs(step.HeatFlux-30/0.27) > 1e-9 {
+			t.Errorf("hour %d: q = %.6f, want %.6f", step.Hour, step.HeatFlux, 30/0.27)
+		}
+		if want := 20 - 30*0.13/0.27; math.Abs(step.SurfaceTemperature-want) > 1e-9 {
+			t.Errorf("hour %d: θsi = %.6f, want %.6f", step.Hour, step.SurfaceTemperature, want)
+		}
+	}
+}
+
+func TestRunPeriodic(t *testing.T) {
+	// After ten days of a sinusoidal outdoor temperature the amplitude of the
+	// indoor heat flux approaches |Y12| · Δθe of ISO 13786, and its peak lags
+	// the outdoor trough by the time shift
+	result := concrete()
+	properties := calculations.DynamicProperties(result)
+
+	const amplitude = 10.0
+	outdoor := make([]float64, 240)
+	for hour := range outdoor {
+		outdoor[hour] = amplitude * math.Cos(2*math.Pi*float64(hour)/24)
+	}
+	simulation := Run(result, models.SimulationInputs{IndoorTemperature: 20, OutdoorTemperatures: outdoor})
+
+	// Fourier coefficients of the heat flux over the last day, its mean
+	// U·20 K drops out
+	var cosine, sine float64
+	for _, step := range simulation.Steps[len(outdoor)-24:] {
+		angle := 2 * math.Pi * float64(step.Hour) / 24
+		cosine += step.HeatFlux * math.Cos(angle) / 12
+		sine += step.HeatFlux * math.Sin(angle) / 12
+	}
+
+	want := properties.PeriodicTransmittance * amplitude
+	if got := math.Hypot(cosine, sine); math.Abs(got-want) > 0.03*want {
+		t.Errorf("heat flux amplitude = %.4f W/m², want %.4f ± 3 %%", got, want)
+	}
+	// The heat flux peaks Δt after the outdoor trough at 12 h
+	peak := math.Mod(math.Atan2(sine, cosine)*24/(2*math.Pi)+24, 24)
+	if shift := peak - 12; math.Abs(shift-properties.TimeShift) > 0.25 {
+		t.Errorf("heat flux peaks %.2f h after the trough, want %.2f h ± 0.25 h", shift, properties.TimeShift)
+	}
+}
+
+func TestRunMassless(t *testing.T) {
+	// Without heat capacity the construction follows the outdoor air at once
+	result := calculations.Evaluate(models.InsulationResult{
+		BaseLayers: []models.InsulationLayer{{Material: models.Material{Name: "Mineral wool", Lambda: 0.04}, Thickness: 200}},
+		HeatFlow:   models.HeatFlowHorizontal,
+	})
+	simulation := Run(result, models.SimulationInputs{IndoorTemperature: 20, OutdoorTemperatures: []float64{0, -10}})
+
+	if len(simulation.Nodes) != 0 {
+		t.Errorf("got %d nodes, want none", len(simulation.Nodes))
+	}
+	if got, want := simulation.Steps[1].HeatFlux, 30*result.TotalUValue; math.Abs(got-want) > 1e-9 {
+		t.Errorf("q = %.6f, want %.6f", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+// Package units converts between the metric values the calculations and the
+// database work in and the unit system a user reads and types them in.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// System is the unit system a user prefers
+type System string
+
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+var Systems = []System{Metric, Imperial}
+
+func (s System) Label() string {
+	if s == Imperial {
+		return "Imperial (in, R-value, BTU/h·ft²·°F)"
+	}
+	return "Metric (mm, m²K/W, W/m²K)"
+}
+
+// ParseSystem checks a submitted unit system
+func ParseSystem(value string) (System, error) {
+	for _, system := range Systems {
+		if string(system) == value {
+			return system, nil
+		}
+	}
+
+	return Metric, errors.New("unknown unit system")
+}
+
+// Quantity is the kind of a value, which decides its unit
+type Quantity int
+
+const (
+	Thickness             Quantity = iota // mm or in
+	Length                                // m or ft
+	Area                                  // m² or ft²
+	Temperature                           // °C or °F
+	TemperatureDifference                 // K or °F, also for degree-days
+	UValue                                // W/m²K or BTU/h·ft²·°F
+	Resistance                            // m²K/W or h·ft²·°F/BTU
+	Conductivity                          // W/mK or BTU·in/h·ft²·°F
+	LinearTransmittance                   // Ψ in W/mK or BTU/h·ft·°F
+	Density                               // kg/m³ or lb/ft³
+	PerArea                               // an amount per m² or per ft², costs and carbon
+	PerVolume                             // an amount per m³ or per ft³, prices and carbon
+	SpecificHeat                          // J/kgK or BTU/lb·°F
+	HeatCapacity                          // areal, kJ/m²K or BTU/ft²·°F
+	HeatFlux                              // W/m² or BTU/h·ft²
+	HeatTransfer                          // H in W/K or BTU/h·°F
+	Power                                 // heat loads, kW or kBTU/h
+	Volume                                // m³ or ft³
+	Energy                                // kWh or kBTU
+	EnergyPerArea                         // kWh/m² or kBTU/ft²
+)
+
+// unit is how a quantity reads in one system: imperial = metric·scale + offset
+type unit struct {
+	symbol string
+	scale  float64
+	offset float64
+}
+
+var metricUnits = map[Quantity]unit{
+	Thickness:             {"mm", 1, 0},
+	Length:                {"m", 1, 0},
+	Area:                  {"m²", 1, 0},
+	Temperature:           {"°C", 1, 0},
+	TemperatureDifference: {"K", 1, 0},
+	UValue:                {"W/m²K", 1, 0},
+	Resistance:            {"m²K/W", 1, 0},
+	Conductivity:          {"W/mK", 1, 0},
+	LinearTransmittance:   {"W/mK", 1, 0},
+	Density:               {"kg/m³", 1, 0},
+	PerArea:               {"/m²", 1, 0},
+	PerVolume:             {"/m³", 1, 0},
+	SpecificHeat:          {"J/kgK", 1, 0},
+	HeatCapacity:          {"kJ/m²K", 1, 0},
+	HeatFlux:              {"W/m²", 1, 0},
+	HeatTransfer:          {"W/K", 1, 0},
+	Power:                 {"kW", 1, 0},
+	Volume:                {"m³", 1, 0},
+	Energy:                {"kWh", 1, 0},
+	EnergyPerArea:         {"kWh/m²", 1, 0},
+}
+
+var imperialUnits = map[Quantity]unit{
+	Thickness:             {"in", 1 / 25.4, 0},
+	Length:                {"ft", 1 / 0.3048, 0},
+	Area:                  {"ft²", 1 / (0.3048 * 0.3048), 0},
+	Temperature:           {"°F", 1.8, 32},
+	TemperatureDifference: {"°F", 1.8, 0},
+	UValue:                {"BTU/h·ft²·°F", 0.176110, 0},
+	Resistance:            {"h·ft²·°F/BTU", 5.678263, 0},
+	Conductivity:          {"BTU·in/h·ft²·°F", 6.933472, 0},
+	LinearTransmittance:   {"BTU/h·ft·°F", 0.577789, 0},
+	Density:               {"lb/ft³", 0.06242796, 0},
+	PerArea:               {"/ft²", 0.3048 * 0.3048, 0},
+	PerVolume:             {"/ft³", 0.3048 * 0.3048 * 0.3048, 0},
+	SpecificHeat:          {"BTU/lb·°F", 2.388459e-4, 0},
+	HeatCapacity:          {"BTU/ft²·°F", 0.0489196, 0},
+	HeatFlux:              {"BTU/h·ft²", 0.316998, 0},
+	HeatTransfer:          {"BTU/h·°F", 1.895634, 0},
+	Power:                 {"kBTU/h", 3.412142, 0},
+	Volume:                {"ft³", 1 / (0.3048 * 0.3048 * 0.3048), 0},
+	Energy:                {"kBTU", 3.412142, 0},
+	EnergyPerArea:         {"kBTU/ft²", 0.316998, 0},
+}
+
+func (s System) unit(quantity Quantity) unit {
+	if s == Imperial {
+		return imperialUnits[quantity]
+	}
+	return metricUnits[quantity]
+}
+
+// Symbol returns the unit of a quantity, the per-area and per-volume ones
+// start with a slash to follow an amount such as $ or kgCO2e
+func (s System) Symbol(quantity Quantity) string {
+	return s.unit(quantity).symbol
+}
+
+// FromMetric converts a metric value to the system
+func (s System) FromMetric(quantity Quantity, value float64) float64 {
+	unit := s.unit(quantity)
+
+	return value*unit.scale + unit.offset
+}
+
+// ToMetric converts a value of the system back to metric
+func (s System) ToMetric(quantity Quantity, value float64) float64 {
+	unit := s.unit(quantity)
+
+	return (value - unit.offset) / unit.scale
+}
+
+// Format shows a metric value in the system with its unit
+func (s System) Format(quantity Quantity, value float64, decimals int) string {
+	symbol := s.Symbol(quantity)
+	if strings.HasPrefix(symbol, "/") {
+		return fmt.Sprintf("%.*f%s", decimals, s.FromMetric(quantity, value), symbol)
+	}
+
+	return fmt.Sprintf("%.*f %s", decimals, s.FromMetric(quantity, value), symbol)
+}
+
+// Number shows a metric value in the system without its unit, for table
+// cells under a labelled header
+func (s System) Number(quantity Quantity, value float64, decimals int) string {
+	return fmt.Sprintf("%.*f", decimals, s.FromMetric(quantity, value))
+}
+
+// Amount shows a metric value per area or per volume after the amount it
+// counts, such as "2.50 kgCO2e/ft²"
+func (s System) Amount(quantity Quantity, value float64, decimals int, amount string) string {
+	return fmt.Sprintf("%.*f %s%s", decimals, s.FromMetric(quantity, value), amount, s.Symbol(quantity))
+}
+
+// Input returns a metric value converted for a form field, rounded so the
+// conversion does not show through
+func (s System) Input(quantity Quantity, value float64) string {
+	converted := s.FromMetric(quantity, value)
+	if s != Metric {
+		converted = math.Round(converted*1e6) / 1e6
+	}
+
+	return strconv.FormatFloat(converted, 'f', -1, 64)
+}
+
+// Parse reads a form value typed in the system and returns it in metric
+func (s System) Parse(quantity Quantity, value string) (float64, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.ToMetric(quantity, number), nil
+}
@@ -0,0 +1,154 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+// quantities lists every quantity of the package
+var quantities = []Quantity{
+	Thickness, Length, Area, Temperature, TemperatureDifference, UValue,
+	Resistance, Conductivity, LinearTransmittance, Density, PerArea,
+	PerVolume, SpecificHeat, HeatCapacity, HeatFlux, HeatTransfer, Power,
+	Volume, Energy, EnergyPerArea,
+}
+
+func TestFromMetric(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity Quantity
+		metric   float64
+		imperial float64
+		within   float64
+	}{
+		{"room temperature", Temperature, 20, 68, 1e-9},
+		{"freezing point", Temperature, 0, 32, 1e-9},
+		{"temperature difference", TemperatureDifference, 10, 18, 1e-9},
+		{"an inch", Thickness, 25.4, 1, 1e-9},
+		{"a foot", Length, 0.3048, 1, 1e-9},
+		{"a square metre", Area, 1, 10.7639, 1e-4},
+		{"a cubic metre", Volume, 1, 35.3147, 1e-4},
+		{"U-value", UValue, 1, 0.176110, 1e-6},
+		{"R-value of 100 mm at λ 0.035", Resistance, 0.1 / 0.035, 16.2236, 1e-4},
+		{"conductivity", Conductivity, 0.04, 0.277339, 1e-6},
+		{"ψ", LinearTransmittance, 0.1, 0.0577789, 1e-7},
+		{"density", Density, 1000, 62.42796, 1e-5},
+		{"price per area", PerArea, 10, 0.92903, 1e-5},
+		{"specific heat", SpecificHeat, 1000, 0.2388459, 1e-7},
+		{"heat flux", HeatFlux, 10, 3.16998, 1e-5},
+		{"design load", Power, 1, 3.412142, 1e-6},
+		{"annual demand", Energy, 1000, 3412.142, 1e-3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Imperial.FromMetric(tt.quantity, tt.metric); math.Abs(got-tt.imperial) > tt.within {
+				t.Errorf("imperial = %.7f, want %.7f", got, tt.imperial)
+			}
+			if got := Metric.FromMetric(tt.quantity, tt.metric); got != tt.metric {
+				t.Errorf("metric = %f, want it unchanged", got)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	values := []float64{-20, 0, 0.035, 1, 250, 2400}
+	for _, system := range Systems {
+		for _, quantity := range quantities {
+			if system.Symbol(quantity) == "" {
+				t.Errorf("%s has no symbol for quantity %d", system, quantity)
+			}
+			for _, value := range values {
+				if got := system.ToMetric(quantity, system.FromMetric(quantity, value)); math.Abs(got-value) > 1e-9*math.Max(1, math.Abs(value)) {
+					t.Errorf("%s quantity %d: %g converts back to %g", system, quantity, value, got)
+				}
+				// Input rounds to six decimals, so allow for that in the unit of the system
+				parsed, err := system.Parse(quantity, system.Input(quantity, value))
+				if err != nil {
+					t.Fatalf("%s quantity %d: %v", system, quantity, err)
+				}
+				if within := 1e-6 / system.unit(quantity).scale; math.Abs(parsed-value) > within {
+					t.Errorf("%s quantity %d: %g parses back as %g", system, quantity, value, parsed)
+				}
+			}
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		system   System
+		quantity Quantity
+		value    float64
+		decimals int
+		want     string
+	}{
+		{Metric, Temperature, 20, 1, "20.0 °C"},
+		{Imperial, Temperature, 20, 1, "68.0 °F"},
+		{Imperial, Thickness, 254, 1, "10.0 in"},
+		{Metric, UValue, 0.1046, 3, "0.105 W/m²K"},
+		{Imperial, Resistance, 9.558336, 1, "54.3 h·ft²·°F/BTU"},
+		{Metric, PerArea, 12.5, 2, "12.50/m²"},
+		{Imperial, PerArea, 10, 2, "0.93/ft²"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.system.Format(tt.quantity, tt.value, tt.decimals); got != tt.want {
+			t.Errorf("Format = %q, want %q", got, tt.want)
+		}
+	}
+
+	if got := Imperial.Amount(PerArea, 10, 2, "kgCO2e"); got != "0.93 kgCO2e/ft²" {
+		t.Errorf("Amount = %q, want %q", got, "0.93 kgCO2e/ft²")
+	}
+	if got := Imperial.Number(Temperature, -10, 0); got != "14" {
+		t.Errorf("Number = %q, want %q", got, "14")
+	}
+	if got := Imperial.Input(Thickness, 100); got != "3.937008" {
+		t.Errorf("Input = %q, want %q", got, "3.937008")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		system   System
+		quantity Quantity
+		value    string
+		metric   float64
+		invalid  bool
+	}{
+		{Metric, Thickness, "150", 150, false},
+		{Imperial, Thickness, " 6 ", 152.4, false},
+		{Imperial, Temperature, "-4", -20, false},
+		{Imperial, Resistance, "20", 20 / 5.678263, false},
+		{Metric, Thickness, "", 0, true},
+		{Imperial, Thickness, "6 in", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.system.Parse(tt.quantity, tt.value)
+		if tt.invalid {
+			if err == nil {
+				t.Errorf("Parse(%q) = %g, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.value, err)
+		} else if math.Abs(got-tt.metric) > 1e-9 {
+			t.Errorf("Parse(%q) = %g, want %g", tt.value, got, tt.metric)
+		}
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	for _, system := range Systems {
+		if got, err := ParseSystem(string(system)); err != nil || got != system {
+			t.Errorf("ParseSystem(%q) = %q, %v", system, got, err)
+		}
+	}
+	if got, err := ParseSystem("furlongs"); err == nil || got != Metric {
+		t.Errorf("ParseSystem(furlongs) = %q, %v, want metric and an error", got, err)
+	}
+}
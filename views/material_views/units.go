@@ -0,0 +1,44 @@
+package material_views
+
+import (
+	"github.com/kaloszer/insulationCalcHtmx/units"
+)
+
+// inputStep keeps the step of a metric field and lets converted values
+// through in any other system
+func inputStep(system units.System, step string) string {
+	if system != units.Metric {
+		return "any"
+	}
+	return step
+}
+
+// withUnit appends the unit of a quantity to a field label
+func withUnit(label string, system units.System, quantity units.Quantity) string {
+	return label + " (" + system.Symbol(quantity) + ")"
+}
+
+// uValueDecimals adds a decimal to U-values outside metric, where they are
+// about six times smaller
+func uValueDecimals(system units.System, decimals int) int {
+	if system != units.Metric {
+		return decimals + 1
+	}
+	return decimals
+}
+
+// thicknessDecimals shows whole millimetres, or inches to a hundredth
+func thicknessDecimals(system units.System) int {
+	if system != units.Metric {
+		return 2
+	}
+	return 0
+}
+
+// placeholder picks the example value of a field for the unit system
+func placeholder(system units.System, metric, imperial string) string {
+	if system == units.Imperial {
+		return imperial
+	}
+	return metric
+}